@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures a Scanner built with New
+type Option func(*Scanner) error
+
+// WithRate limits the scan to rps requests per second
+func WithRate(rps int) Option {
+	return func(s *Scanner) error {
+		s.SetRateLimit(rps)
+		return nil
+	}
+}
+
+// WithWorkers runs cross-user tests on n concurrent workers (1 = sequential)
+func WithWorkers(n int) Option {
+	return func(s *Scanner) error {
+		s.SetWorkers(n)
+		return nil
+	}
+}
+
+// WithProxy routes all traffic through proxyURL (TLS verification is disabled)
+func WithProxy(proxyURL string) Option {
+	return func(s *Scanner) error {
+		return s.SetProxy(proxyURL)
+	}
+}
+
+// WithTimeout sets the per-request timeout
+func WithTimeout(d time.Duration) Option {
+	return func(s *Scanner) error {
+		s.SetTimeout(d)
+		return nil
+	}
+}
+
+// WithLogger receives progress and warnings; without it the scanner is silent
+func WithLogger(l Logger) Option {
+	return func(s *Scanner) error {
+		s.SetLogger(l)
+		return nil
+	}
+}
+
+// New builds a scanner for embedding in other Go programs. Unlike the CLI it
+// prints nothing unless a Logger is supplied.
+func New(users []User, requests []APIRequest, opts ...Option) (*Scanner, error) {
+	s := NewScanner(users, requests)
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// SetWorkers sets how many workers Scan uses (values below 1 mean sequential)
+func (s *Scanner) SetWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	s.workers = n
+}
+
+// Scan captures baselines and runs every configured test, concurrently when
+// more than one worker is set. Findings come back sorted by severity, then
+// endpoint and user pair. It stops early when ctx is cancelled and
+// returns ErrBaselineDrift (with the findings so far) on a strict drift failure.
+// With SetCheckpoint, an incomplete run leaves its progress for the next.
+func (s *Scanner) Scan(ctx context.Context) ([]Finding, error) {
+	s.tuneConnPool()
+	s.warnCredentialCap()
+	if err := s.PrefetchTokens(ctx); err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	var err error
+	if s.workers > 1 {
+		findings, err = s.RunWithBaselineConcurrent(ctx, s.workers)
+	} else {
+		findings, err = s.RunWithBaseline(ctx)
+	}
+	s.finishCheckpoint(err == nil && ctx.Err() == nil && !s.limitHit)
+	sortFindings(findings)
+	return findings, err
+}
@@ -1,23 +1,47 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"net/http"
-	"strings"
+	"reflect"
 	"time"
 )
 
 // Baseline stores original response data for comparison
 type Baseline struct {
-	StatusCode int
-	BodySize   int
-	BodyHash   string
+	StatusCode   int
+	BodySize     int
+	BodyHash     string
+	HeadOnly     bool              // size came from a HEAD Content-Length; BodyHash is empty
+	Body         []byte            // kept only for --diff
+	ItemIDs      []string          // item identifiers when the response is a JSON list
+	Sensitive    map[string]string // person-identifying and secret JSON fields, by path
+	SizeMismatch bool              // Content-Length disagreed with the bytes read
+	Denied       bool              // the user was refused their own request (4xx)
+	Headers      map[string]string // evidence headers (see SetEvidenceHeaders)
+	Drifted      bool              // re-captured by a drift check after the original shifted
+}
+
+// deniedStatus reports whether a user's own request was refused. A 429 is
+// throttling, not a verdict on access.
+func deniedStatus(code int) bool {
+	return code >= 400 && code < 500 && code != http.StatusTooManyRequests
 }
 
 // BaselineMap stores baselines per endpoint+user
 type BaselineMap map[string]map[string]Baseline // endpoint -> user -> baseline
 
+// drifted reports whether a drift check replaced one of endpoint's baselines
+func (b BaselineMap) drifted(endpoint string) bool {
+	for _, baseline := range b[endpoint] {
+		if baseline.Drifted {
+			return true
+		}
+	}
+	return false
+}
+
 // CaptureBaselines gets the legitimate response for each user on each endpoint
 func (s *Scanner) CaptureBaselines() BaselineMap {
 	baselines := make(BaselineMap)
@@ -27,144 +51,200 @@ func (s *Scanner) CaptureBaselines() BaselineMap {
 		baselines[endpoint] = make(map[string]Baseline)
 
 		for _, user := range s.Users {
-			if verbose {
-				fmt.Printf("📸 Baseline: %s as %s\n", endpoint, user.Name)
-			}
-
-			// Personalize request for the baseline user
-			// We try to find ANY user's ID in the URL and swap it to these user's ID
-			var testReq *http.Request
-			
-			// Try to find which user (if any) currently owns the URL params
-			var sourceUser *User
-			for _, potentialSource := range s.Users {
-				// Check if potentialSource's params match what's in the URL
-				if s.urlContainsParams(req.URL, potentialSource.Params) {
-					u := potentialSource
-					sourceUser = &u
-					break
-				}
-			}
-
-			if sourceUser != nil {
-				// We found the owner, swap to target user
-				// Use buildRequestWithSwap where "Attacker" is the target user (for Auth)
-				// and "Victim" is the target user (for Params)
-				// Wait, buildRequestWithSwap swaps FROM attacker TO victim
-				// Here we want to swap FROM sourceUser TO target user
-				
-				// We need a helper that swaps Source -> Target
-				// But reuse buildRequestWithSwap logic:
-				// It calls BuildSwappedURL(url, attacker.Params, victim.Params)
-				// ie. Replaces AttackerID with VictimID
-				
-				// So: Attacker=sourceUser, Victim=user
-				// But we need the Auth of 'user'
-				
-				url := BuildSwappedURL(req.URL, sourceUser.Params, user.Params)
-				body := BuildSwappedBody(req.Body, sourceUser.Params, user.Params)
-				testReq, _ = http.NewRequest(req.Method, url, strings.NewReader(body))
-				
-				// Add Auth headers for 'user'
-				for key, val := range user.Headers {
-					testReq.Header.Set(key, val)
-				}
-				// Add original headers
-				for key, val := range req.Headers {
-					if _, exists := user.Headers[key]; !exists {
-						testReq.Header.Set(key, val)
-					}
-				}
-			} else {
-				// No known ID found, or placeholders used
-				// Default to simple buildRequest (handles placeholders)
-				testReq = s.buildRequest(req, user, user.Params)
-			}
-
-			if testReq == nil {
+			// The anonymous context owns nothing, so it never needs a baseline
+			if user.IsAnonymous() {
 				continue
 			}
 
-			resp, err := s.executeRequest(testReq)
-			if err != nil {
-				continue
-			}
+			s.log.Debugf("📸 Baseline: %s as %s\n", endpoint, user.Name)
 
-			body, _ := io.ReadAll(resp.Body)
-			resp.Body.Close()
-
-			baselines[endpoint][user.Name] = Baseline{
-				StatusCode: resp.StatusCode,
-				BodySize:   len(body),
+			baseline, ok := s.captureBaseline(req, user)
+			if !ok {
+				continue
 			}
+			baselines[endpoint][user.Name] = baseline
 
 			// Rate limit
 			time.Sleep(s.rateDelay)
 		}
 	}
 
+	s.baselines = baselines
 	return baselines
 }
 
-// RunWithBaseline executes scan with baseline comparison for accuracy
-func (s *Scanner) RunWithBaseline() []Finding {
-	findings := []Finding{}
+// SetBaselineHead makes GET baselines use HEAD and Content-Length instead of
+// downloading the body. Baselines captured this way carry no body hash.
+func (s *Scanner) SetBaselineHead(enabled bool) {
+	s.baselineHead = enabled
+}
+
+// captureBaseline issues a single request as the user against their own resources
+func (s *Scanner) captureBaseline(req APIRequest, user User) (Baseline, bool) {
+	// Personalize request for the baseline user: swap whichever user's
+	// IDs are baked into the URL (or fill placeholders) with this user's
+	url, reqBody := s.personalize(req, user)
+	testReq := s.buildRequest(APIRequest{
+		Method:  req.Method,
+		URL:     url,
+		Headers: req.Headers,
+		Body:    reqBody,
+	}, user, nil)
 
-	if verbose {
-		fmt.Println("📊 Capturing baselines...")
-		fmt.Println()
+	if testReq == nil {
+		return Baseline{}, false
 	}
 
-	baselines := s.CaptureBaselines()
+	if s.baselineHead && testReq.Method == http.MethodGet {
+		if baseline, ok := s.captureHeadBaseline(testReq, user); ok {
+			return baseline, true
+		}
+	}
 
-	if verbose {
-		fmt.Println()
-		fmt.Println("🚀 Starting IDOR tests...")
-		fmt.Println()
+	resp, err := s.executeAs(user, testReq)
+	if err != nil {
+		return Baseline{}, false
 	}
 
-	for _, req := range s.Requests {
-		endpoint := fmt.Sprintf("%s %s", req.Method, req.URL)
+	body, mismatch := s.readBody(resp)
+	s.noteResponseSize(req, user.Name, "", len(body))
+	resp.Body.Close()
+
+	baseline := Baseline{
+		StatusCode:   resp.StatusCode,
+		BodySize:     len(body),
+		BodyHash:     hashBody(body),
+		SizeMismatch: mismatch,
+		Denied:       deniedStatus(resp.StatusCode),
+		Headers:      s.keptHeaders(resp),
+	}
+	baseline.ItemIDs, _ = extractItemIDs(body)
+	baseline.Sensitive = sensitiveFields(body)
+	if s.keepsBaselineBody() && len(body) <= maxDiffBodySize {
+		baseline.Body = body
+	}
+	return baseline, true
+}
+
+// captureHeadBaseline sizes a GET baseline from a HEAD response's Content-Length.
+// It reports false when the server omits the header or rejects HEAD, so the
+// caller can fall back to a full GET.
+func (s *Scanner) captureHeadBaseline(getReq *http.Request, user User) (Baseline, bool) {
+	headReq := getReq.Clone(getReq.Context())
+	headReq.Method = http.MethodHead
+	headReq.Body = nil
+	headReq.GetBody = nil
 
-		if verbose {
-			fmt.Printf("🔍 Testing: %s\n", endpoint)
+	resp, err := s.executeAs(user, headReq)
+	if err != nil {
+		return Baseline{}, false
+	}
+	resp.Body.Close()
+
+	if resp.ContentLength < 0 || resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		return Baseline{}, false
+	}
+
+	return Baseline{
+		StatusCode: resp.StatusCode,
+		BodySize:   int(resp.ContentLength),
+		HeadOnly:   true,
+		Denied:     deniedStatus(resp.StatusCode),
+		Headers:    s.keptHeaders(resp),
+	}, true
+}
+
+// RunWithBaseline executes scan with baseline comparison for accuracy.
+// It returns ErrBaselineDrift (with the findings so far) if a strict drift check fails.
+func (s *Scanner) RunWithBaseline(ctx context.Context) ([]Finding, error) {
+	findings := []Finding{}
+
+	// Cancelled early once the finding limit is reached
+	ctx, cancel := s.limitContext(ctx)
+	defer cancel()
+	findings = s.restoreCheckpoint(findings)
+
+	s.log.Debugf("📊 Capturing baselines...\n\n")
+
+	baselines := s.CaptureBaselines()
+
+	s.log.Debugf("\n🚀 Starting IDOR tests...\n\n")
+
+	for i, req := range s.testRequests() {
+		if ctx.Err() != nil {
+			break
 		}
 
+		endpoint := fmt.Sprintf("%s %s", req.Method, req.URL)
+
+		s.log.Debugf("🔍 Testing: %s\n", endpoint)
+
 		// Cross-user access test with baseline comparison
-		for _, attacker := range s.Users {
-			for _, victim := range s.Users {
-				if attacker.Name == victim.Name {
-					continue
-				}
-
-				f := s.testCrossUserWithBaseline(req, attacker, victim, baselines)
-				if f != nil {
-					findings = append(findings, *f)
-				}
-
-				// Rate limit
-				time.Sleep(s.rateDelay)
+		for _, pair := range s.testPairs() {
+			if ctx.Err() != nil {
+				break
+			}
+
+			key := jobKey(req, pair.attacker, &pair.victim)
+			if s.checkpoint.isDone(key) {
+				continue
+			}
+
+			f := s.testCrossUserWithBaseline(req, pair.attacker, pair.victim, baselines)
+			if f == nil && ctx.Err() != nil {
+				break // interrupted mid-test: leave it for a resumed run
+			}
+			if f != nil {
+				findings = s.addFinding(findings, *f)
 			}
+			s.checkpoint.complete(key)
+
+			// Rate limit
+			time.Sleep(s.rateDelay)
+		}
+
+		if ctx.Err() != nil {
+			break
 		}
 
 		// No auth test
-		f := s.testNoAuth(req)
-		if f != nil {
-			findings = append(findings, *f)
+		if key := jobKey(req, User{}, nil); !s.checkpoint.isDone(key) {
+			f := s.testNoAuth(req, baselines)
+			if f == nil && ctx.Err() != nil {
+				break
+			}
+			if f != nil {
+				findings = s.addFinding(findings, *f)
+			}
+			s.checkpoint.complete(key)
+
+			time.Sleep(s.rateDelay)
 		}
 
-		time.Sleep(s.rateDelay)
+		// Periodic re-baseline to catch shifting responses
+		if err := s.maybeCheckDrift(baselines, i+1); err != nil {
+			return s.markDrifted(findings), err
+		}
 	}
 
-	return findings
+	// Numeric ID enumeration (opt-in)
+	findings = append(findings, s.EnumerateIDs(ctx, baselines)...)
+	findings = append(findings, s.EnumerateSiblings(ctx, baselines)...)
+	findings = append(findings, s.FuzzIDTypes(ctx, baselines)...)
+
+	for _, f := range s.largeResponseFindings() {
+		findings = s.addFinding(findings, f)
+	}
+
+	return s.markDrifted(findings), nil
 }
 
 func (s *Scanner) testCrossUserWithBaseline(req APIRequest, attacker User, victim User, baselines BaselineMap) *Finding {
 	endpoint := fmt.Sprintf("%s %s", req.Method, req.URL)
 
 	// Get victim's baseline (what they should see)
-	victimBaseline, ok := baselines[endpoint][victim.Name]
-	if !ok {
+	victimBaseline, ok := s.victimBaseline(baselines, endpoint, attacker, victim)
+	if !ok || s.swapsNothing(req, attacker, victim) {
 		return nil
 	}
 
@@ -175,45 +255,65 @@ func (s *Scanner) testCrossUserWithBaseline(req APIRequest, attacker User, victi
 		return nil
 	}
 
-	resp, err := s.executeRequest(testReq)
+	resp, err := s.executeAs(attacker, testReq)
 	if err != nil {
-		return nil
+		return requestErrorFinding(req, attacker.Name, victim.Name, err)
 	}
 	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
-
-	// Compare against baseline
-	if resp.StatusCode == 200 || resp.StatusCode == 201 {
-		// Critical: same size as victim's legitimate response
-		sizeDiff := abs(len(body) - victimBaseline.BodySize)
-		if sizeDiff < 50 && victimBaseline.BodySize > 0 {
-			return &Finding{
-				Severity:    "CRITICAL",
-				Endpoint:    req.URL,
-				Method:      req.Method,
-				Description: fmt.Sprintf("User '%s' accessed '%s's data (response matches victim's baseline)", attacker.Name, victim.Name),
-				Evidence:    fmt.Sprintf("Status: %d, Size: %d bytes (victim baseline: %d bytes)", resp.StatusCode, len(body), victimBaseline.BodySize),
-				Timestamp:   time.Now(),
-			}
-		}
+	body, mismatch := s.readBody(resp)
+	s.noteResponseSize(req, attacker.Name, victim.Name, len(body))
 
-		// High: got 200 but different size (might be partial leak or different data)
-		if len(body) > 50 {
-			return &Finding{
-				Severity:    "HIGH",
-				Endpoint:    req.URL,
-				Method:      req.Method,
-				Description: fmt.Sprintf("User '%s' got 200 accessing '%s's resource (size differs from baseline)", attacker.Name, victim.Name),
-				Evidence:    fmt.Sprintf("Status: %d, Size: %d bytes (victim baseline: %d bytes)", resp.StatusCode, len(body), victimBaseline.BodySize),
-				Timestamp:   time.Now(),
-			}
-		}
+	// A created resource landing under the victim's IDs is a cross-user write
+	if f := checkLocationIDOR(req, attacker, victim, resp); f != nil {
+		s.attachHeaders(f, resp, victimBaseline)
+		return withExchange(f, testReq, body)
+	}
+
+	own, hasOwn := baselines[endpoint][attacker.Name]
+	if f := s.crossUserFinding(req, attacker, victim, resp.StatusCode, body, mismatch, victimBaseline, own); f != nil {
+		s.attachDiff(f, own, hasOwn, body)
+		s.attachHeaders(f, resp, victimBaseline)
+		return withExchange(f, testReq, body)
 	}
 
 	return nil
 }
 
+// swapsNothing reports whether the pair's cross-user request is just the
+// attacker's own: no ID in its URL, body or headers belongs to the victim
+// (/health, /me), so its response can't be the victim's data. A guest
+// context's request is the victim's own, so it is always tested.
+func (s *Scanner) swapsNothing(req APIRequest, attacker, victim User) bool {
+	if attacker.IsAnonymous() {
+		return false
+	}
+	url, body := s.swappedRequest(req, attacker, victim)
+	ownURL, ownBody := s.personalize(req, attacker)
+	if url != ownURL || body != ownBody || !reflect.DeepEqual(fillHeaders(req.Headers, victim.Params), fillHeaders(req.Headers, attacker.Params)) {
+		return false
+	}
+	s.log.Debugf("⏭️  Skipping %s → %s on %s %s: nothing in the request names the victim\n",
+		attacker.Name, victim.Name, req.Method, req.URL)
+	return true
+}
+
+// victimBaseline returns the victim's baseline for endpoint. It reports false
+// when there is none or the victim was denied their own request, since a
+// refusal leaves no legitimate response to compare the attacker's against.
+func (s *Scanner) victimBaseline(baselines BaselineMap, endpoint string, attacker, victim User) (Baseline, bool) {
+	baseline, ok := baselines[endpoint][victim.Name]
+	if !ok {
+		return Baseline{}, false
+	}
+	if baseline.Denied {
+		s.log.Debugf("⏭️  Skipping %s → %s on %s: victim's baseline was denied (%d)\n",
+			attacker.Name, victim.Name, endpoint, baseline.StatusCode)
+		return Baseline{}, false
+	}
+	return baseline, true
+}
+
 func abs(x int) int {
 	if x < 0 {
 		return -x
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
 	"strings"
 )
 
@@ -14,4 +18,51 @@ func (s *Scanner) urlContainsParams(urlStr string, params map[string]string) boo
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// personalize rewrites a request's URL and body to target the user's own resources,
+// swapping out whichever user's IDs are currently baked in (or filling placeholders)
+func (s *Scanner) personalize(req APIRequest, user User) (string, string) {
+	var sourceParams map[string]string
+	for _, potentialSource := range s.Users {
+		if s.urlContainsParams(req.URL, potentialSource.Params) {
+			sourceParams = potentialSource.Params
+			break
+		}
+	}
+
+	return s.swapURL(req, sourceParams, user.Params), s.swapBody(req, sourceParams, user.Params)
+}
+
+// hashBody returns a hex SHA-256 digest of a response body
+func hashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// readBody reads a response body and checks it against the declared
+// Content-Length. Comparisons always use the bytes actually read; the bool
+// reports a significant mismatch (truncation, a misbehaving proxy) so findings
+// built on this size can say so.
+func (s *Scanner) readBody(resp *http.Response) ([]byte, bool) {
+	body, _ := io.ReadAll(resp.Body)
+	if resp.ContentLength < 0 || !sizeMismatch(int(resp.ContentLength), len(body)) {
+		return body, false
+	}
+
+	if resp.Request != nil {
+		s.log.Debugf("   ⚠️  %s %s: Content-Length %d but read %d bytes\n",
+			resp.Request.Method, resp.Request.URL, resp.ContentLength, len(body))
+	}
+	return body, true
+}
+
+// sizeMismatch reports whether read differs from declared by more than 1% or
+// 16 bytes, whichever is larger
+func sizeMismatch(declared, read int) bool {
+	slack := declared / 100
+	if slack < 16 {
+		slack = 16
+	}
+	return abs(declared-read) > slack
+}
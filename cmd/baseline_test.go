@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// --baseline-head sizes GET baselines from HEAD and falls back to GET when
+// the server refuses HEAD. Without a body hash the IDOR is still caught, as
+// a size match (HIGH) rather than a confirmed one.
+func TestBaselineHead(t *testing.T) {
+	for _, refuseHead := range []bool{false, true} {
+		inner := selftestHandler()
+		srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if refuseHead && r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			inner.ServeHTTP(w, r)
+		}))
+		req := getRequest(srv.URL + "/api/users/{user_id}")
+		s := fastScanner(selftestUsers(), []APIRequest{req})
+		s.SetBaselineHead(true)
+
+		baseline := s.CaptureBaselines()["GET "+req.URL]["alice"]
+		if baseline.HeadOnly == refuseHead || baseline.StatusCode != http.StatusOK || baseline.BodySize == 0 {
+			t.Errorf("refuseHead=%v: alice's baseline = %+v", refuseHead, baseline)
+		}
+
+		findings, err := s.Scan(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := SeverityHigh
+		if refuseHead {
+			want = SeverityCritical
+		}
+		if len(findings) == 0 {
+			t.Errorf("refuseHead=%v: IDOR not found", refuseHead)
+		}
+		for _, f := range findings {
+			if f.Kind != kindCrossUser || f.Severity != want {
+				t.Errorf("refuseHead=%v: got %s %s finding, want %s cross-user", refuseHead, f.Severity, f.Kind, want)
+			}
+		}
+	}
+}
+
+func TestSizeMismatch(t *testing.T) {
+	for _, tc := range []struct {
+		declared, read int
+		want           bool
+	}{
+		{100, 100, false},
+		{100, 84, false}, // within the 16-byte floor
+		{100, 83, true},
+		{100000, 99000, false}, // within 1%
+		{100000, 98999, true},
+	} {
+		if got := sizeMismatch(tc.declared, tc.read); got != tc.want {
+			t.Errorf("sizeMismatch(%d, %d) = %v, want %v", tc.declared, tc.read, got, tc.want)
+		}
+	}
+}
+
+// A body cut short of its Content-Length is flagged; an unknown length isn't
+func TestReadBodyFlagsShortBody(t *testing.T) {
+	s := NewScanner(nil, nil)
+	for _, tc := range []struct {
+		length int64
+		want   bool
+	}{
+		{500, true},
+		{-1, false},
+		{int64(len("partial body")), false},
+	} {
+		resp := &http.Response{ContentLength: tc.length, Body: io.NopCloser(strings.NewReader("partial body"))}
+		body, mismatch := s.readBody(resp)
+		if string(body) != "partial body" || mismatch != tc.want {
+			t.Errorf("Content-Length %d: readBody = %q, %v; want mismatch %v", tc.length, body, mismatch, tc.want)
+		}
+	}
+}
+
+// A victim refused their own request has no legitimate response to compare
+// against, so nobody is tested against them there
+func TestDeniedVictimSkipped(t *testing.T) {
+	for _, workers := range []int{1, 2} {
+		srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Only alice may use the admin API, on any account
+			if r.Header.Get("Authorization") != "Bearer alice-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"account":"` + r.URL.Path + `"}`))
+		}))
+		s := fastScanner(selftestUsers(), []APIRequest{getRequest(srv.URL + "/admin/users/{user_id}")})
+		s.SetWorkers(workers)
+		findings, err := s.Scan(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(findings) != 0 {
+			t.Errorf("workers=%d: findings = %+v", workers, findings)
+		}
+
+		bobTested := false
+		for _, r := range srv.requests() {
+			if r.Path == "/admin/users/456" && r.Header.Get("Authorization") == "Bearer alice-token" {
+				bobTested = true
+			}
+		}
+		if bobTested {
+			t.Errorf("workers=%d: alice was tested against bob, whose own request was denied", workers)
+		}
+	}
+}
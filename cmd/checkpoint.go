@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// checkpointEvery is how often progress is written while a scan runs
+const checkpointEvery = 5 * time.Second
+
+// checkpointState is the on-disk form of a checkpoint
+type checkpointState struct {
+	Version  int       `json:"version"`
+	Done     []string  `json:"done"` // job keys, see jobKey
+	Findings []Finding `json:"findings"`
+}
+
+// checkpoint tracks which cross-user, no-auth and ID sweep tests have
+// completed and the findings reported so far, so an interrupted scan can pick
+// up where it stopped. It is safe for concurrent use; writes replace the file atomically.
+type checkpoint struct {
+	mu       sync.Mutex
+	path     string
+	done     map[string]bool
+	findings []Finding
+	restored map[string]bool // IDs of findings loaded from the file
+	saved    time.Time
+	err      error // first save error, reported once
+}
+
+// SetCheckpoint keeps progress in path. When the file exists, the tests it
+// lists are skipped and its findings are reported again; it is removed once
+// a scan completes. Returns how many completed tests were loaded.
+func (s *Scanner) SetCheckpoint(path string) (int, error) {
+	c := &checkpoint{
+		path:     path,
+		done:     make(map[string]bool),
+		findings: []Finding{},
+		restored: make(map[string]bool),
+		saved:    time.Now(),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	if err == nil {
+		var file checkpointState
+		if err := json.Unmarshal(data, &file); err != nil {
+			return 0, fmt.Errorf("checkpoint %s: %w", path, err)
+		}
+		for _, key := range file.Done {
+			c.done[key] = true
+		}
+		for _, f := range file.Findings {
+			if f.ID == "" || c.restored[f.ID] {
+				continue
+			}
+			c.restored[f.ID] = true
+			c.findings = append(c.findings, f)
+		}
+	}
+
+	s.checkpoint = c
+	return len(c.done), nil
+}
+
+// jobKey identifies a test across runs: the request, the attacker context
+// (with its credential set) and the victim. A nil victim is the no-auth test.
+func jobKey(req APIRequest, attacker User, victim *User) string {
+	endpoint := fmt.Sprintf("%s %s", req.Method, req.URL)
+	if victim == nil {
+		return endpoint + " | no-auth"
+	}
+	who := attacker.Name
+	if attacker.credential != "" {
+		who += "#" + attacker.credential
+	}
+	return fmt.Sprintf("%s | %s -> %s", endpoint, who, victim.Name)
+}
+
+// sweepKey identifies one user's ID sweep (enumeration or siblings) of a
+// request across runs
+func sweepKey(req APIRequest, user User, kind string) string {
+	return fmt.Sprintf("%s %s | %s as %s", req.Method, req.URL, kind, user.Name)
+}
+
+// isDone reports whether a previous run already completed the test
+func (c *checkpoint) isDone(key string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[key]
+}
+
+// complete marks a test done, saving if the last save is old enough
+func (c *checkpoint) complete(key string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.done[key] = true
+	c.saveIfDue()
+}
+
+// record keeps a reported finding
+func (c *checkpoint) record(f Finding) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.findings = append(c.findings, f)
+	c.saveIfDue()
+}
+
+// wasRestored reports whether the finding came back from the checkpoint file,
+// so a phase that re-runs on resume doesn't report it twice
+func (c *checkpoint) wasRestored(id string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.restored[id]
+}
+
+func (c *checkpoint) saveIfDue() {
+	if time.Since(c.saved) < checkpointEvery {
+		return
+	}
+	c.save()
+}
+
+// save writes the checkpoint to a temporary file and renames it into place,
+// so an interrupt never leaves a half-written file. Callers hold c.mu.
+func (c *checkpoint) save() error {
+	c.saved = time.Now()
+
+	file := checkpointState{Version: 1, Done: make([]string, 0, len(c.done)), Findings: c.findings}
+	for key := range c.done {
+		file.Done = append(file.Done, key)
+	}
+	sort.Strings(file.Done)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return c.fail(err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".tmp*")
+	if err != nil {
+		return c.fail(err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return c.fail(err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return c.fail(err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		os.Remove(tmp.Name())
+		return c.fail(err)
+	}
+	return nil
+}
+
+func (c *checkpoint) fail(err error) error {
+	if c.err == nil {
+		c.err = err
+	}
+	return err
+}
+
+// restoreCheckpoint reports the findings loaded from the checkpoint as if
+// this run had found them, counting them toward the finding limit
+func (s *Scanner) restoreCheckpoint(findings []Finding) []Finding {
+	if s.checkpoint == nil {
+		return findings
+	}
+	s.checkpoint.mu.Lock()
+	restored := make([]Finding, 0, len(s.checkpoint.restored))
+	for _, f := range s.checkpoint.findings {
+		if s.checkpoint.restored[f.ID] {
+			restored = append(restored, f)
+		}
+	}
+	s.checkpoint.mu.Unlock()
+
+	for _, f := range restored {
+		if f.ErrorClass != "" {
+			s.seenRequestError(f)
+		}
+		if s.onFinding != nil {
+			s.onFinding(f)
+		}
+		if f.Severity != SeverityInfo && !s.known[f.ID] {
+			s.checkFindingLimit(f)
+		}
+		findings = append(findings, f)
+	}
+	if len(restored) > 0 {
+		s.log.Debugf("⏩ Restored %d findings from %s\n", len(restored), s.checkpoint.path)
+	}
+	return findings
+}
+
+// finishCheckpoint removes the checkpoint after a complete scan and saves it
+// otherwise, so the next run resumes the remaining tests
+func (s *Scanner) finishCheckpoint(complete bool) {
+	c := s.checkpoint
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if complete {
+		if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.log.Warnf("⚠️  Could not remove checkpoint %s: %v\n", c.path, err)
+		}
+		return
+	}
+	if err := c.save(); err != nil {
+		s.log.Warnf("⚠️  Could not save checkpoint %s: %v\n", c.path, err)
+	} else if c.err != nil {
+		s.log.Warnf("⚠️  Some checkpoint saves failed (%v); the final save succeeded\n", c.err)
+	}
+}
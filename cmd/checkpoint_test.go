@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// resuming skips the tests the checkpoint lists, reports its findings again
+// and removes the file once the scan completes
+func TestCheckpointResume(t *testing.T) {
+	srv := newRecordingServer(t, selftestHandler())
+	req := getRequest(srv.URL + "/api/users/{user_id}")
+	users := selftestUsers()
+
+	restored := Finding{ID: "restored-1", Severity: SeverityCritical, Kind: kindCrossUser, Attacker: "alice", Victim: "bob", Method: "GET", Endpoint: "/api/users/{user_id}"}
+	data, err := json.Marshal(checkpointState{
+		Version:  1,
+		Done:     []string{jobKey(req, users[0], &users[1])},
+		Findings: []Finding{restored, restored},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "scan.checkpoint")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := fastScanner(users, []APIRequest{req})
+	done, err := s.SetCheckpoint(path)
+	if err != nil || done != 1 {
+		t.Fatalf("SetCheckpoint = %d, %v; want 1 done", done, err)
+	}
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range srv.requests() {
+		if r.Path == "/api/users/456" && r.Header.Get("Authorization") == "Bearer alice-token" {
+			t.Error("alice → bob was re-run despite the checkpoint")
+		}
+	}
+	count, bobToAlice := 0, false
+	for _, f := range findings {
+		if f.ID == restored.ID {
+			count++
+		}
+		if f.Attacker == "bob" && f.Victim == "alice" && f.Severity == SeverityCritical {
+			bobToAlice = true
+		}
+	}
+	if count != 1 {
+		t.Errorf("restored finding reported %d times, want once", count)
+	}
+	if !bobToAlice {
+		t.Error("bob → alice not tested after resuming")
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("checkpoint left behind after a complete scan: %v", err)
+	}
+}
+
+func TestCheckpointRejectsCorruptFile(t *testing.T) {
+	path := writeTemp(t, "scan.checkpoint", "{not json")
+	if _, err := NewScanner(nil, nil).SetCheckpoint(path); err == nil {
+		t.Error("corrupt checkpoint accepted")
+	}
+}
+
+func TestJobKey(t *testing.T) {
+	req := getRequest("https://api.example.com/api/users/{user_id}")
+	alice := User{Name: "alice", credential: "cookie"}
+	bob := User{Name: "bob"}
+
+	if got, want := jobKey(req, alice, &bob), "GET https://api.example.com/api/users/{user_id} | alice#cookie -> bob"; got != want {
+		t.Errorf("jobKey = %q, want %q", got, want)
+	}
+	if got, want := jobKey(req, User{}, nil), "GET https://api.example.com/api/users/{user_id} | no-auth"; got != want {
+		t.Errorf("no-auth jobKey = %q, want %q", got, want)
+	}
+}
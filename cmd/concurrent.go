@@ -1,8 +1,8 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"sync"
 	"time"
 )
@@ -12,33 +12,39 @@ type ScanJob struct {
 	Request  APIRequest
 	Attacker User
 	Victim   User
-	Baseline Baseline
+	Baseline Baseline // victim's
+	Own      Baseline // attacker's, for --diff
+	HasOwn   bool
+
+	done func() // marks the job finished for its endpoint
 }
 
 // ScanResult contains the result of a scan job
 type ScanResult struct {
 	Finding *Finding
 	Error   error
+	Key     string // the job's checkpoint key
+
+	endpointDone bool // every job for one endpoint has a result (no finding)
 }
 
-// RunWithBaselineConcurrent executes scan with worker pool
-func (s *Scanner) RunWithBaselineConcurrent(workers int) []Finding {
+// RunWithBaselineConcurrent executes scan with worker pool.
+// Like RunWithBaseline, it returns ErrBaselineDrift if a strict drift check fails.
+func (s *Scanner) RunWithBaselineConcurrent(ctx context.Context, workers int) ([]Finding, error) {
 	if workers <= 0 {
 		workers = 5 // Default
 	}
 
-	if verbose {
-		fmt.Println("📊 Capturing baselines...")
-		fmt.Println()
-	}
+	// Cancelled early if a strict drift check fails or the finding limit is reached
+	ctx, cancel := s.limitContext(ctx)
+	defer cancel()
+	var driftErr error
+
+	s.log.Debugf("📊 Capturing baselines...\n\n")
 
 	baselines := s.CaptureBaselines()
 
-	if verbose {
-		fmt.Println()
-		fmt.Printf("🚀 Starting IDOR tests with %d workers...\n", workers)
-		fmt.Println()
-	}
+	s.log.Debugf("\n🚀 Starting IDOR tests with %d workers...\n\n", workers)
 
 	// Create job channel
 	jobs := make(chan ScanJob, 100)
@@ -48,115 +54,189 @@ func (s *Scanner) RunWithBaselineConcurrent(workers int) []Finding {
 	var wg sync.WaitGroup
 	for i := 0; i < workers; i++ {
 		wg.Add(1)
-		go s.worker(jobs, results, &wg)
+		go s.worker(ctx, jobs, results, &wg)
 	}
 
-	// Queue jobs in a separate goroutine to prevent deadlock
+	// Queue jobs in a separate goroutine to prevent deadlock. Each endpoint
+	// gets a waiter that reports once all of its jobs have results, so drift
+	// checks run on tested endpoints rather than queued ones.
+	var waiters sync.WaitGroup
 	go func() {
-		for _, req := range s.Requests {
+		for _, req := range s.testRequests() {
+			if ctx.Err() != nil {
+				break
+			}
+
 			endpoint := fmt.Sprintf("%s %s", req.Method, req.URL)
 
-			if verbose {
-				fmt.Printf("🔍 Queuing: %s\n", endpoint)
-			}
+			s.log.Debugf("🔍 Queuing: %s\n", endpoint)
 
-			for _, attacker := range s.Users {
-				for _, victim := range s.Users {
-					if attacker.Name == victim.Name {
-						continue
-					}
-
-					baseline, ok := baselines[endpoint][victim.Name]
-					if !ok {
-						continue
-					}
-
-					jobs <- ScanJob{
-						Request:  req,
-						Attacker: attacker,
-						Victim:   victim,
-						Baseline: baseline,
-					}
+			var endpointJobs sync.WaitGroup
+			for _, pair := range s.testPairs() {
+				if ctx.Err() != nil {
+					break
+				}
+
+				// Drift checks in the collector may replace baselines meanwhile
+				s.baselineMu.RLock()
+				baseline, ok := s.victimBaseline(baselines, endpoint, pair.attacker, pair.victim)
+				own, hasOwn := baselines[endpoint][pair.attacker.Name]
+				s.baselineMu.RUnlock()
+				if !ok || s.swapsNothing(req, pair.attacker, pair.victim) {
+					continue
+				}
+
+				if s.checkpoint.isDone(jobKey(req, pair.attacker, &pair.victim)) {
+					continue
+				}
+
+				endpointJobs.Add(1)
+				jobs <- ScanJob{
+					Request:  req,
+					Attacker: pair.attacker,
+					Victim:   pair.victim,
+					Baseline: baseline,
+					Own:      own,
+					HasOwn:   hasOwn,
+					done:     endpointJobs.Done,
 				}
 			}
+
+			waiters.Add(1)
+			go func() {
+				defer waiters.Done()
+				endpointJobs.Wait()
+				results <- ScanResult{endpointDone: true}
+			}()
 		}
 		close(jobs)
 	}()
 
-	// Wait for workers in a separate goroutine
+	// Wait for workers, then for the endpoint waiters they release. Every
+	// waiter is added before jobs closes, so none is missed.
 	go func() {
 		wg.Wait()
+		waiters.Wait()
 		close(results)
 	}()
 
 	// Collect results
-	findings := []Finding{}
+	findings := s.restoreCheckpoint([]Finding{})
+	tested := 0
 	for result := range results {
+		// Periodic re-baseline to catch shifting responses
+		if result.endpointDone {
+			tested++
+			if driftErr == nil && ctx.Err() == nil {
+				if err := s.maybeCheckDrift(baselines, tested); err != nil {
+					driftErr = err
+					cancel()
+				}
+			}
+			continue
+		}
+
+		// Results still in flight when the finding limit hit are drained, not
+		// kept, and left for a resumed run
+		if result.Finding != nil && s.limitHit {
+			continue
+		}
 		if result.Finding != nil {
-			findings = append(findings, *result.Finding)
+			findings = s.addFinding(findings, *result.Finding)
 		}
+		s.checkpoint.complete(result.Key)
 	}
 
 	// Also run no-auth tests (sequential, usually fewer)
-	for _, req := range s.Requests {
-		f := s.testNoAuth(req)
+	for _, req := range s.testRequests() {
+		if ctx.Err() != nil {
+			break
+		}
+		key := jobKey(req, User{}, nil)
+		if s.checkpoint.isDone(key) {
+			continue
+		}
+		f := s.testNoAuth(req, baselines)
+		if f == nil && ctx.Err() != nil {
+			break
+		}
 		if f != nil {
-			findings = append(findings, *f)
+			findings = s.addFinding(findings, *f)
 		}
+		s.checkpoint.complete(key)
 		time.Sleep(s.rateDelay)
 	}
 
-	return findings
+	if driftErr != nil {
+		return s.markDrifted(findings), driftErr
+	}
+
+	// Numeric ID enumeration (opt-in)
+	findings = append(findings, s.EnumerateIDs(ctx, baselines)...)
+	findings = append(findings, s.EnumerateSiblings(ctx, baselines)...)
+	findings = append(findings, s.FuzzIDTypes(ctx, baselines)...)
+
+	for _, f := range s.largeResponseFindings() {
+		findings = s.addFinding(findings, f)
+	}
+
+	return s.markDrifted(findings), nil
 }
 
-func (s *Scanner) worker(jobs <-chan ScanJob, results chan<- ScanResult, wg *sync.WaitGroup) {
+func (s *Scanner) worker(ctx context.Context, jobs <-chan ScanJob, results chan<- ScanResult, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for job := range jobs {
+		// Drain remaining jobs without sending once the scan is cancelled
+		if ctx.Err() != nil {
+			job.finish()
+			continue
+		}
+
+		// A job cut short by cancellation has no answer; leave it unfinished
+		// so a resumed run tries it again
 		finding := s.executeScanJob(job)
-		results <- ScanResult{Finding: finding}
+		if finding == nil && ctx.Err() != nil {
+			job.finish()
+			continue
+		}
+		results <- ScanResult{Finding: finding, Key: jobKey(job.Request, job.Attacker, &job.Victim)}
+		job.finish()
 		time.Sleep(s.rateDelay)
 	}
 }
 
+// finish marks the job done for its endpoint's waiter, if it has one
+func (job ScanJob) finish() {
+	if job.done != nil {
+		job.done()
+	}
+}
+
 func (s *Scanner) executeScanJob(job ScanJob) *Finding {
 	testReq := s.buildRequestWithSwap(job.Request, job.Attacker, job.Victim)
 	if testReq == nil {
 		return nil
 	}
 
-	resp, err := s.executeRequest(testReq)
+	resp, err := s.executeAs(job.Attacker, testReq)
 	if err != nil {
-		return nil
+		return requestErrorFinding(job.Request, job.Attacker.Name, job.Victim.Name, err)
 	}
 	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
-
-	// Compare against baseline
-	if resp.StatusCode == 200 || resp.StatusCode == 201 {
-		sizeDiff := abs(len(body) - job.Baseline.BodySize)
-		if sizeDiff < 50 && job.Baseline.BodySize > 0 {
-			return &Finding{
-				Severity:    "CRITICAL",
-				Endpoint:    job.Request.URL,
-				Method:      job.Request.Method,
-				Description: fmt.Sprintf("User '%s' accessed '%s's data (response matches victim's baseline)", job.Attacker.Name, job.Victim.Name),
-				Evidence:    fmt.Sprintf("Status: %d, Size: %d bytes (victim baseline: %d bytes)", resp.StatusCode, len(body), job.Baseline.BodySize),
-				Timestamp:   time.Now(),
-			}
-		}
+	body, mismatch := s.readBody(resp)
+	s.noteResponseSize(job.Request, job.Attacker.Name, job.Victim.Name, len(body))
 
-		if len(body) > 50 {
-			return &Finding{
-				Severity:    "HIGH",
-				Endpoint:    job.Request.URL,
-				Method:      job.Request.Method,
-				Description: fmt.Sprintf("User '%s' got 200 accessing '%s's resource (size differs from baseline)", job.Attacker.Name, job.Victim.Name),
-				Evidence:    fmt.Sprintf("Status: %d, Size: %d bytes (victim baseline: %d bytes)", resp.StatusCode, len(body), job.Baseline.BodySize),
-				Timestamp:   time.Now(),
-			}
-		}
+	if f := checkLocationIDOR(job.Request, job.Attacker, job.Victim, resp); f != nil {
+		s.attachHeaders(f, resp, job.Baseline)
+		return withExchange(f, testReq, body)
+	}
+
+	if f := s.crossUserFinding(job.Request, job.Attacker, job.Victim, resp.StatusCode, body, mismatch, job.Baseline, job.Own); f != nil {
+		s.attachDiff(f, job.Own, job.HasOwn, body)
+		s.attachHeaders(f, resp, job.Baseline)
+		return withExchange(f, testReq, body)
 	}
 
 	return nil
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var configInitForce bool
+
+var configInitCmd = &cobra.Command{
+	Use:   "config-init [file]",
+	Short: "Write a commented config template with every option and its default",
+	Long: `Config-init writes a YAML config (default .idor-scan.yaml) listing every
+scan option under its flag name with the default value and a short
+explanation, plus examples of the config-only sections. It refuses to
+overwrite an existing file unless --force is given.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runConfigInit,
+}
+
+func init() {
+	configInitCmd.Flags().BoolVar(&configInitForce, "force", false, "Overwrite an existing file")
+	rootCmd.AddCommand(configInitCmd)
+}
+
+// secretFlags get a hint to use their environment variable instead
+var secretFlags = map[string]string{
+	"proxy-pass": "IDOR_SCAN_PROXY_PASS",
+	"basic-auth": "IDOR_SCAN_BASIC_AUTH",
+}
+
+// configOnlySections documents the options that have no flag
+const configOnlySections = `
+# --- Config-only sections ---
+
+# Pin where object IDs live when auto-detection guesses wrong.
+# id_locations:
+#   - pattern: "/api/orders"     # substring of the request URL
+#     method: POST               # optional
+#     param: order_id            # user param that supplies the ID
+#     json_path: "$.order.id"    # ID inside the JSON body
+#   - pattern: "/api/v2/accounts"
+#     param: account_id
+#     path_index: 3              # URL path segment (0 = first segment)
+
+# Tag findings on sensitive endpoints and raise them to a minimum severity.
+# sensitivity:
+#   - pattern: "/payments"       # substring of the request URL
+#     tag: payments
+#     min_severity: HIGH         # CRITICAL, HIGH or MEDIUM
+
+# Show severities under your own labels in text, CSV and HTML. JSON and
+# JSONL keep the canonical level.
+# severity_labels:
+#   critical: P0
+#   high: P1
+#   medium: P2
+#   info: P4
+`
+
+// configTemplate renders every scan flag as a commented-out YAML key, so the
+// file changes nothing until a line is uncommented
+func configTemplate() string {
+	var b strings.Builder
+	b.WriteString("# idor-scan configuration\n")
+	b.WriteString("# Every option is listed commented out with its default; uncomment a line to\n")
+	b.WriteString("# change it. Keys are the long flag names, and command-line flags and\n")
+	b.WriteString("# IDOR_SCAN_* environment variables override them.\n")
+
+	flags := pflag.NewFlagSet("config", pflag.ContinueOnError)
+	flags.AddFlagSet(rootCmd.PersistentFlags())
+	flags.AddFlagSet(rootCmd.Flags())
+
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Name == "config" || f.Name == "help" || f.Hidden {
+			return
+		}
+
+		fmt.Fprintf(&b, "\n# %s\n", f.Usage)
+		if env, ok := secretFlags[f.Name]; ok {
+			fmt.Fprintf(&b, "# Prefer setting %s over storing this in a file.\n", env)
+		}
+		fmt.Fprintf(&b, "# %s: %s\n", f.Name, yamlDefault(f))
+	})
+
+	b.WriteString(configOnlySections)
+	return b.String()
+}
+
+// yamlDefault renders a flag's default as a YAML value
+func yamlDefault(f *pflag.Flag) string {
+	switch f.Value.Type() {
+	case "stringSlice", "stringArray":
+		inner := strings.Trim(f.DefValue, "[]")
+		if inner == "" {
+			return "[]"
+		}
+		items := strings.Split(inner, ",")
+		for i, item := range items {
+			items[i] = strconv.Quote(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case "string":
+		return strconv.Quote(f.DefValue)
+	default:
+		return f.DefValue
+	}
+}
+
+func runConfigInit(cmd *cobra.Command, args []string) {
+	path := ".idor-scan.yaml"
+	if len(args) == 1 {
+		path = args[0]
+	}
+
+	if _, err := os.Stat(path); err == nil && !configInitForce {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists (use --force to overwrite)\n", path)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(path, []byte(configTemplate()), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing config: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("📝 Wrote %s\n", path)
+}
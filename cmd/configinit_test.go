@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// Uncommenting every option in the template loads cleanly and changes
+// nothing: each line carries its flag's default
+func TestConfigTemplateRoundTrips(t *testing.T) {
+	template := configTemplate()
+
+	lines := []string{}
+	listed := map[string]bool{}
+	for _, line := range strings.Split(template, "\n") {
+		key, _, ok := strings.Cut(strings.TrimPrefix(line, "# "), ": ")
+		if !ok || rootCmd.Flags().Lookup(key) == nil {
+			continue
+		}
+		listed[key] = true
+		lines = append(lines, strings.TrimPrefix(line, "# "))
+	}
+
+	rootCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Name != "config" && f.Name != "help" && !f.Hidden && !listed[f.Name] {
+			t.Errorf("--%s missing from the template", f.Name)
+		}
+	})
+
+	loadConfig(t, writeTemp(t, "all.yaml", strings.Join(lines, "\n")+"\n"))
+	rootCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if listed[f.Name] && f.Value.String() != f.DefValue {
+			t.Errorf("--%s = %q after loading the template, want default %q", f.Name, f.Value.String(), f.DefValue)
+		}
+	})
+}
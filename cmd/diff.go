@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxDiffBodySize caps the baseline bodies kept in memory for --diff
+const maxDiffBodySize = 1 << 20
+
+// maxDiffEntries caps how many changed fields a finding records
+const maxDiffEntries = 50
+
+// DiffEntry is one field that differs between what the attacker received and
+// what they get for their own resource
+type DiffEntry struct {
+	Path   string `json:"path"`   // e.g. $.orders[0].total
+	Change string `json:"change"` // "added" or "changed"
+	Value  string `json:"value"`
+	Was    string `json:"was,omitempty"` // attacker's own value, for "changed"
+}
+
+// secretKeywords mark JSON keys whose values are masked in diffs, on top of
+// the header auth keywords
+var secretKeywords = []string{"password", "passwd", "secret", "apikey", "api_key", "private", "ssn"}
+
+// SetDiff keeps baseline bodies so cross-user findings can record which
+// fields differ from the attacker's own response
+func (s *Scanner) SetDiff(enabled bool) {
+	s.diff = enabled
+}
+
+// attachDiff records on f the fields of body that differ from the attacker's
+// own baseline for the endpoint
+func (s *Scanner) attachDiff(f *Finding, own Baseline, ok bool, body []byte) {
+	if !s.diff || !ok || own.Body == nil {
+		return
+	}
+	f.Diff = jsonDiff(own.Body, body)
+}
+
+// jsonDiff compares two JSON documents field by field and returns the fields
+// that are new or changed in got. Non-JSON responses produce no diff.
+func jsonDiff(own, got []byte) []DiffEntry {
+	gotFields, ok := flattenJSON(got)
+	if !ok {
+		return nil
+	}
+	ownFields, _ := flattenJSON(own)
+
+	paths := make([]string, 0, len(gotFields))
+	for p := range gotFields {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	entries := []DiffEntry{}
+	for _, p := range paths {
+		val := gotFields[p]
+		was, seen := ownFields[p]
+		if seen && was == val {
+			continue
+		}
+
+		entry := DiffEntry{Path: p, Change: "added", Value: maskSecret(p, val)}
+		if seen {
+			entry.Change = "changed"
+			entry.Was = maskSecret(p, was)
+		}
+		entries = append(entries, entry)
+
+		if len(entries) == maxDiffEntries {
+			break
+		}
+	}
+
+	return entries
+}
+
+// flattenJSON maps every leaf of a JSON document to its path
+func flattenJSON(data []byte) (map[string]string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, false
+	}
+
+	fields := make(map[string]string)
+	flattenValue(doc, "$", fields)
+	return fields, true
+}
+
+func flattenValue(v interface{}, path string, fields map[string]string) {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for key, child := range node {
+			flattenValue(child, path+"."+key, fields)
+		}
+	case []interface{}:
+		for i, child := range node {
+			flattenValue(child, fmt.Sprintf("%s[%d]", path, i), fields)
+		}
+	default:
+		raw, _ := json.Marshal(node)
+		fields[path] = string(raw)
+	}
+}
+
+// leafKey returns the last object key in a flattened path ($.a.b[2] -> b)
+func leafKey(path string) string {
+	key := path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		key = path[i+1:]
+	}
+	if i := strings.Index(key, "["); i >= 0 {
+		key = key[:i]
+	}
+	return key
+}
+
+// maskSecret hides values under secret-looking keys and anything shaped like a JWT
+func maskSecret(path, value string) string {
+	key := leafKey(path)
+	lower := strings.ToLower(key)
+	secret := isAuthName(key) || strings.HasPrefix(value, `"eyJ`)
+	for _, kw := range secretKeywords {
+		if strings.Contains(lower, kw) {
+			secret = true
+		}
+	}
+
+	if secret {
+		return `"***"`
+	}
+	return value
+}
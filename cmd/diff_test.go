@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestJSONDiff(t *testing.T) {
+	own := []byte(`{"id":123,"name":"Alice","orders":[{"total":10}],"theme":"dark"}`)
+	got := []byte(`{"id":456,"name":"Bob","orders":[{"total":99}],"theme":"dark","ssn":"444-55-6666","token":"eyJhbGciOi"}`)
+
+	want := []DiffEntry{
+		{Path: "$.id", Change: "changed", Value: "456", Was: "123"},
+		{Path: "$.name", Change: "changed", Value: `"Bob"`, Was: `"Alice"`},
+		{Path: "$.orders[0].total", Change: "changed", Value: "99", Was: "10"},
+		{Path: "$.ssn", Change: "added", Value: `"***"`},
+		{Path: "$.token", Change: "added", Value: `"***"`},
+	}
+	if diff := jsonDiff(own, got); !reflect.DeepEqual(diff, want) {
+		t.Errorf("jsonDiff =\n%+v\nwant\n%+v", diff, want)
+	}
+
+	if diff := jsonDiff(own, []byte("<html>")); diff != nil {
+		t.Errorf("non-JSON response diffed: %+v", diff)
+	}
+}
+
+// --diff attaches the leaked fields to cross-user findings
+func TestScanAttachesDiff(t *testing.T) {
+	srv := newRecordingServer(t, selftestHandler())
+	s := fastScanner(selftestUsers(), []APIRequest{getRequest(srv.URL + "/api/users/{user_id}")})
+	s.SetDiff(true)
+
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) == 0 {
+		t.Fatal("no findings")
+	}
+	for _, f := range findings {
+		fields := map[string]bool{}
+		for _, d := range f.Diff {
+			fields[d.Path] = true
+		}
+		if !fields["$.email"] || !fields["$.ssn"] {
+			t.Errorf("%s→%s diff = %+v, want email and ssn", f.Attacker, f.Victim, f.Diff)
+		}
+	}
+}
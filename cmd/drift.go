@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrBaselineDrift is returned when a strict drift check aborts the scan
+var ErrBaselineDrift = errors.New("baseline drift detected")
+
+// DriftCheck configures periodic re-baselining during a scan
+type DriftCheck struct {
+	Every     int     // re-check after this many endpoints (0 disables)
+	Threshold float64 // relative body-size change that counts as drift
+	Strict    bool    // abort instead of warning
+}
+
+// SetDriftCheck enables re-capturing a sampled baseline every N endpoints
+func (s *Scanner) SetDriftCheck(every int, threshold float64, strict bool) {
+	if every <= 0 {
+		s.drift = nil
+		return
+	}
+	s.drift = &DriftCheck{Every: every, Threshold: threshold, Strict: strict}
+}
+
+// maybeCheckDrift runs a drift check when `tested` endpoints have completed
+// (every test on them has a result) and the configured interval has elapsed
+func (s *Scanner) maybeCheckDrift(baselines BaselineMap, tested int) error {
+	if s.drift == nil || len(s.Requests) == 0 || tested%s.drift.Every != 0 {
+		return nil
+	}
+	return s.checkDrift(baselines, tested/s.drift.Every)
+}
+
+// checkDrift re-captures one sampled endpoint/user baseline and compares it to
+// the original. Sampling rotates through endpoints and users on each round.
+func (s *Scanner) checkDrift(baselines BaselineMap, round int) error {
+	req := s.Requests[(round-1)%len(s.Requests)]
+	endpoint := fmt.Sprintf("%s %s", req.Method, req.URL)
+
+	for i := range s.Users {
+		user := s.Users[(round+i)%len(s.Users)]
+		original, ok := baselines[endpoint][user.Name]
+		if !ok {
+			continue
+		}
+
+		current, ok := s.captureBaseline(req, user)
+		if !ok {
+			return nil
+		}
+
+		s.log.Debugf("🔁 Re-baseline: %s as %s (status %d→%d, size %d→%d)\n",
+			endpoint, user.Name, original.StatusCode, current.StatusCode, original.BodySize, current.BodySize)
+
+		if !baselineDrifted(original, current, s.drift.Threshold) {
+			return nil
+		}
+
+		// Later tests compare against the fresh capture; findings on this
+		// endpoint are marked either way
+		current.Drifted = true
+		s.baselineMu.Lock()
+		baselines[endpoint][user.Name] = current
+		s.baselineMu.Unlock()
+		s.log.Warnf("⚠️  Baseline drift on %s as %s: status %d→%d, size %d→%d bytes; its findings are marked unreliable\n",
+			endpoint, user.Name, original.StatusCode, current.StatusCode, original.BodySize, current.BodySize)
+
+		if s.drift.Strict {
+			return fmt.Errorf("%w on %s as %s", ErrBaselineDrift, endpoint, user.Name)
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// markDrifted flags findings on endpoints a drift check re-baselined,
+// including those reported before the drift was found
+func (s *Scanner) markDrifted(findings []Finding) []Finding {
+	for i, f := range findings {
+		if s.baselines.drifted(f.Method + " " + f.Endpoint) {
+			findings[i].BaselineDrift = true
+		}
+	}
+	return findings
+}
+
+// baselineDrifted reports whether a fresh capture diverges from the original
+func baselineDrifted(original, current Baseline, threshold float64) bool {
+	if original.StatusCode != current.StatusCode {
+		return true
+	}
+
+	base := original.BodySize
+	if base == 0 {
+		base = 1
+	}
+	return float64(abs(current.BodySize-original.BodySize))/float64(base) > threshold
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// growingHandler answers like selftestHandler until grow is set, then pads
+// every profile (but not order lists) so re-captured baselines drift
+func growingHandler(grow *atomic.Bool) http.Handler {
+	inner := selftestHandler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !grow.Load() || strings.HasSuffix(r.URL.Path, "/orders") {
+			inner.ServeHTTP(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"path":%q,"padding":%q}`, r.URL.Path, strings.Repeat("x", 500))
+	})
+}
+
+// Only the drifted endpoint's findings are marked, including those reported
+// before the drift check that caught it
+func TestDriftMarksDriftedEndpoint(t *testing.T) {
+	for _, workers := range []int{1, 2} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			var grow atomic.Bool
+			srv := newRecordingServer(t, growingHandler(&grow))
+			requests := []APIRequest{getRequest(srv.URL + "/api/users/123"), getRequest(srv.URL + "/api/users/{user_id}/orders")}
+
+			s := fastScanner(selftestUsers(), requests)
+			s.SetWorkers(workers)
+			s.SetDriftCheck(1, 0.2, false)
+			s.OnFinding(func(f Finding) {
+				grow.Store(true) // the target changes once the first finding is in
+			})
+
+			findings, err := s.Scan(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(findings) == 0 {
+				t.Fatal("no findings")
+			}
+			for _, f := range findings {
+				want := !strings.HasSuffix(f.Endpoint, "/orders")
+				if f.BaselineDrift != want {
+					t.Errorf("%s %s: BaselineDrift = %v, want %v", f.Method, f.Endpoint, f.BaselineDrift, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStrictDriftAborts(t *testing.T) {
+	for _, workers := range []int{1, 2} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			var grow atomic.Bool
+			srv := newRecordingServer(t, growingHandler(&grow))
+			s := fastScanner(selftestUsers(), []APIRequest{getRequest(srv.URL + "/api/users/123")})
+			s.SetWorkers(workers)
+			s.SetDriftCheck(1, 0.2, true)
+			s.OnFinding(func(Finding) { grow.Store(true) })
+
+			if _, err := s.Scan(context.Background()); !errors.Is(err, ErrBaselineDrift) {
+				t.Fatalf("Scan error = %v, want ErrBaselineDrift", err)
+			}
+		})
+	}
+}
+
+// The concurrent path must re-baseline an endpoint only after its cross-user
+// tests have run, not as soon as they are queued
+func TestConcurrentDriftCheckWaitsForResults(t *testing.T) {
+	srv := newRecordingServer(t, selftestHandler())
+	s := fastScanner(selftestUsers(), []APIRequest{getRequest(srv.URL + "/api/users/123")})
+	s.SetWorkers(2)
+	s.SetDriftCheck(1, 0.2, true)
+	if _, err := s.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 2 baselines, 2 cross-user tests, then the re-baseline
+	seen := srv.requests()
+	if len(seen) < 5 {
+		t.Fatalf("server saw %d requests, want at least 5", len(seen))
+	}
+	crossUser := 0
+	for _, r := range seen[2:4] {
+		owner := map[string]string{"/api/users/123": "Bearer alice-token", "/api/users/456": "Bearer bob-token"}[r.Path]
+		if r.Header.Get("Authorization") != owner {
+			crossUser++
+		}
+	}
+	if crossUser != 2 {
+		t.Errorf("requests 3-4 = %+v, want both cross-user tests before the re-baseline", seen[2:4])
+	}
+}
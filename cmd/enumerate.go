@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnumRange describes a sweep of numeric IDs (inclusive)
+type EnumRange struct {
+	Start int
+	End   int
+	Step  int
+}
+
+// maxEnumValues caps how many IDs a single sweep may probe
+const maxEnumValues = 10000
+
+// SetEnumRange enables numeric ID enumeration over [start, end] in increments of step
+func (s *Scanner) SetEnumRange(start, end, step int) error {
+	if step <= 0 {
+		return fmt.Errorf("enum step must be positive, got %d", step)
+	}
+	if end < start {
+		return fmt.Errorf("enum end (%d) is before start (%d)", end, start)
+	}
+	if (end-start)/step+1 > maxEnumValues {
+		return fmt.Errorf("enum range too large: %d values (max %d)", (end-start)/step+1, maxEnumValues)
+	}
+	s.enum = &EnumRange{Start: start, End: end, Step: step}
+	return nil
+}
+
+// enumWriteMethods are left out of ID sweeps unless SetEnumWrites allows
+// them: every probe would create, change or delete whichever object the
+// candidate ID names, and a sweep names thousands of other users' objects.
+var enumWriteMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// SetEnumWrites lets the enumeration and sibling sweeps replay POST, PUT,
+// PATCH and DELETE requests
+func (s *Scanner) SetEnumWrites(enabled bool) {
+	s.sweepAll = enabled
+}
+
+// isNumericID reports whether an ID value is purely numeric
+func isNumericID(v string) bool {
+	if v == "" {
+		return false
+	}
+	for _, c := range v {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// replaceIDSegment swaps the path segment following id.Key with a new value
+func replaceIDSegment(urlStr string, id IDPattern, newVal string) string {
+	parts := strings.Split(urlStr, "/")
+	for i := 1; i < len(parts); i++ {
+		seg := parts[i]
+		suffix := ""
+		if idx := strings.IndexAny(seg, "?#"); idx >= 0 {
+			seg, suffix = seg[:idx], seg[idx:]
+		}
+		if seg == id.Value && strings.ToLower(parts[i-1]) == id.Key {
+			parts[i] = newVal + suffix
+			return strings.Join(parts, "/")
+		}
+	}
+	return urlStr
+}
+
+// EnumerateIDs sweeps the configured numeric range through each detected numeric
+// path ID, using each user's own credentials, and reports which IDs returned a
+// 200 whose body differs from the user's own baseline.
+func (s *Scanner) EnumerateIDs(ctx context.Context, baselines BaselineMap) []Finding {
+	if s.enum == nil {
+		return []Finding{}
+	}
+
+	return s.sweepIDs(ctx, baselines, idSweep{
+		kind:  kindEnumeration,
+		label: fmt.Sprintf("range %d-%d step %d", s.enum.Start, s.enum.End, s.enum.Step),
+		candidates: func(id IDPattern) []string {
+			if id.Kind != "numeric" {
+				return nil
+			}
+			ids := []string{}
+			for n := s.enum.Start; n <= s.enum.End; n += s.enum.Step {
+				ids = append(ids, strconv.Itoa(n))
+			}
+			return ids
+		},
+		describe: func(user User, n int, id IDPattern) string {
+			return fmt.Sprintf("User '%s' can access %d other '%s' objects by enumerating IDs", user.Name, n, id.Key)
+		},
+	})
+}
+
+// idSweep describes one kind of ID probing run by sweepIDs
+type idSweep struct {
+	kind       string                                      // finding kind, qualified by ID key
+	label      string                                      // shown in logs and evidence
+	candidates func(id IDPattern) []string                 // IDs to try in place of id (nil skips it)
+	describe   func(user User, n int, id IDPattern) string // finding description
+}
+
+// sweepIDs replays each endpoint as each user with their own path IDs replaced
+// by the sweep's candidates, producing one finding per endpoint, user and ID
+// whose candidates returned distinct 200 bodies that differ from the user's
+// own baseline
+func (s *Scanner) sweepIDs(ctx context.Context, baselines BaselineMap, sw idSweep) []Finding {
+	findings := []Finding{}
+
+	for _, req := range s.testRequests() {
+		endpoint := fmt.Sprintf("%s %s", req.Method, req.URL)
+		if enumWriteMethods[strings.ToUpper(req.Method)] && !s.sweepAll {
+			s.log.Debugf("⏭️  Not sweeping %s: write method (see --enum-writes)\n", endpoint)
+			continue
+		}
+
+		for _, user := range s.Users {
+			own, ok := baselines[endpoint][user.Name]
+			if !ok || own.StatusCode != 200 || user.CanAttack != nil && !*user.CanAttack {
+				continue
+			}
+			key := sweepKey(req, user, sw.kind)
+			if s.checkpoint.isDone(key) {
+				continue
+			}
+
+			url, body := s.personalize(req, user)
+
+			for _, id := range ExtractIDsFromURL(url) {
+				if id.Location != "path" {
+					continue
+				}
+				candidates := sw.candidates(id)
+				if len(candidates) == 0 {
+					continue
+				}
+
+				s.log.Debugf("🔢 Enumerating %s (%s, %s) as %s\n", endpoint, id.Key, sw.label, user.Name)
+
+				hits := []sweepHit{}
+				for _, candidate := range candidates {
+					if ctx.Err() != nil {
+						return findings
+					}
+					if candidate == id.Value {
+						continue
+					}
+
+					probe := APIRequest{
+						Method:  req.Method,
+						URL:     replaceIDSegment(url, id, candidate),
+						Headers: req.Headers,
+						Body:    body,
+					}
+					testReq := s.buildRequest(probe, user, nil)
+					if testReq == nil {
+						continue
+					}
+
+					resp, err := s.executeAs(user, testReq.WithContext(ctx))
+					if err == nil {
+						respBody, _ := s.readBody(resp)
+						resp.Body.Close()
+
+						// Without a hash (HEAD baselines) any other ID's 200 counts
+						if resp.StatusCode == 200 && len(respBody) > 0 && (own.HeadOnly || hashBody(respBody) != own.BodyHash) {
+							hits = append(hits, sweepHit{candidate, hashBody(respBody)})
+						}
+					}
+
+					select {
+					case <-ctx.Done():
+						return findings
+					case <-time.After(s.rateDelay):
+					}
+				}
+
+				accessible := distinctHits(hits)
+				if dropped := len(hits) - len(accessible); dropped > 0 {
+					s.log.Debugf("   ⏭️  %d IDs returned a body shared with another ID (soft 404?); not counted\n", dropped)
+				}
+				if len(accessible) == 0 {
+					continue
+				}
+
+				s.log.Debugf("   🔓 %d accessible IDs: %s\n", len(accessible), strings.Join(accessible, ", "))
+
+				findings = s.addFinding(findings, Finding{
+					Kind:        findingKind(sw.kind, id.Key),
+					Severity:    SeverityHigh,
+					Endpoint:    req.URL,
+					Method:      req.Method,
+					Description: sw.describe(user, len(accessible), id),
+					Evidence:    fmt.Sprintf("Accessible IDs (%s): %s", sw.label, strings.Join(accessible, ", ")),
+					Timestamp:   time.Now(),
+					Attacker:    user.Name,
+				})
+			}
+			s.checkpoint.complete(key)
+		}
+	}
+
+	return findings
+}
+
+// sweepHit is a candidate ID that returned a 200, with its body's hash
+type sweepHit struct {
+	id   string
+	hash string
+}
+
+// distinctHits returns the IDs whose body no other candidate got. Distinct
+// objects have distinct bodies; one body coming back for several IDs is the
+// API's "not found" page served with a 200.
+func distinctHits(hits []sweepHit) []string {
+	count := make(map[string]int, len(hits))
+	for _, h := range hits {
+		count[h.hash]++
+	}
+	ids := []string{}
+	for _, h := range hits {
+		if count[h.hash] == 1 {
+			ids = append(ids, h.id)
+		}
+	}
+	return ids
+}
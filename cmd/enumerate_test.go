@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Sweeps never replay writes against other users' IDs unless asked to
+func TestEnumerationSkipsWriteMethods(t *testing.T) {
+	for _, writes := range []bool{false, true} {
+		srv := newRecordingServer(t, selftestHandler())
+		requests := []APIRequest{
+			getRequest(srv.URL + "/api/users/{user_id}"),
+			{Method: "DELETE", URL: srv.URL + "/api/users/{user_id}"},
+		}
+		s := fastScanner(selftestUsers(), requests)
+		if err := s.SetEnumRange(120, 125, 1); err != nil {
+			t.Fatal(err)
+		}
+		s.SetEnumWrites(writes)
+		if _, err := s.Scan(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		probes := map[string]int{}
+		for _, r := range srv.requests() {
+			if r.Path != "/api/users/123" && r.Path != "/api/users/456" {
+				probes[r.Method]++
+			}
+		}
+		if probes["GET"] == 0 {
+			t.Errorf("writes=%v: no GET probes sent", writes)
+		}
+		if writes != (probes["DELETE"] > 0) {
+			t.Errorf("writes=%v: sent %d DELETE probes", writes, probes["DELETE"])
+		}
+	}
+}
+
+// An API that answers unknown IDs with a 200 "not found" body only leaks the
+// IDs that return a real object
+func TestEnumerationIgnoresSoft404(t *testing.T) {
+	inner := selftestHandler()
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/users/123" && r.URL.Path != "/api/users/456" {
+			fmt.Fprint(w, `{"error":"not found"}`)
+			return
+		}
+		inner.ServeHTTP(w, r)
+	}))
+	s := fastScanner(selftestUsers(), []APIRequest{getRequest(srv.URL + "/api/users/{user_id}")})
+	if err := s.SetEnumRange(450, 460, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sweeps []Finding
+	for _, f := range findings {
+		if strings.HasPrefix(f.Kind, kindEnumeration) {
+			sweeps = append(sweeps, f)
+		}
+	}
+	if len(sweeps) != 1 || sweeps[0].Attacker != "alice" || !strings.HasSuffix(sweeps[0].Evidence, ": 456") {
+		t.Fatalf("want alice reaching only 456, got %+v", sweeps)
+	}
+}
+
+// A resumed scan skips the sweeps the checkpoint lists as done
+func TestEnumerationResumesFromCheckpoint(t *testing.T) {
+	srv := newRecordingServer(t, selftestHandler())
+	req := getRequest(srv.URL + "/api/users/{user_id}")
+	users := selftestUsers()
+
+	state, _ := json.Marshal(checkpointState{Version: 1, Done: []string{sweepKey(req, users[0], kindEnumeration)}})
+	path := writeTemp(t, "scan.ckpt", string(state))
+
+	s := fastScanner(users, []APIRequest{req})
+	if err := s.SetEnumRange(120, 125, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SetCheckpoint(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	probes := map[string]int{}
+	for _, r := range srv.requests() {
+		if r.Path != "/api/users/123" && r.Path != "/api/users/456" {
+			probes[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]++
+		}
+	}
+	if probes["alice-token"] != 0 || probes["bob-token"] == 0 {
+		t.Errorf("probes by token = %v, want only bob's sweep to run", probes)
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// maxInputSize caps how much a remote collection, spec or HAR may download
+const maxInputSize = 50 << 20
+
+// isRemoteInput reports whether an input flag value is an http(s) URL
+func isRemoteInput(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// inputClient fetches remote inputs through the same proxy, proxy credentials
+// and timeout the scan uses
+func inputClient() (*http.Client, error) {
+	t := newTransport() // environment proxies unless --proxy is set
+	if proxyURL != "" {
+		if err := useProxy(t, proxyURL); err != nil {
+			return nil, fmt.Errorf("proxy: %w", err)
+		}
+	}
+	if proxyUser != "" {
+		addProxyAuth(t, proxyUser, proxyPass)
+	}
+	return &http.Client{Timeout: time.Duration(timeoutSecs) * time.Second, Transport: t}, nil
+}
+
+// localInput returns a local path for an input flag value, downloading URLs to
+// a temp file (keeping the extension) that cleanup removes
+func localInput(name string) (string, func(), error) {
+	if !isRemoteInput(name) {
+		return name, func() {}, nil
+	}
+
+	u, err := url.Parse(name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	client, err := inputClient()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if verbose {
+		fmt.Printf("🌐 Fetching %s\n", redactURL(name))
+	}
+
+	resp, err := client.Get(name)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("fetching %s: %s", redactURL(name), resp.Status)
+	}
+
+	tmp, err := os.CreateTemp("", "idor-scan-input-*"+path.Ext(u.Path))
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.Remove(tmp.Name()) }
+
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, maxInputSize+1))
+	tmp.Close()
+	if err == nil && n > maxInputSize {
+		err = fmt.Errorf("fetching %s: larger than %d MB", redactURL(name), maxInputSize>>20)
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return tmp.Name(), cleanup, nil
+}
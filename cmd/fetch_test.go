@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// A URL input is downloaded to a temp file with the same extension (so the
+// parser can tell its format), which cleanup removes
+func TestLocalInputFetchesURL(t *testing.T) {
+	spec := "openapi: 3.0.0\npaths: {}\n"
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/specs/api.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(spec))
+	}))
+
+	path, cleanup, err := localInput(srv.URL + "/specs/api.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(path) != ".yaml" {
+		t.Errorf("temp file %s lost the .yaml extension", path)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != spec {
+		t.Errorf("temp file holds %q (%v)", data, err)
+	}
+	cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("cleanup left %s behind", path)
+	}
+
+	// Credentials in the URL stay out of the error
+	u := strings.Replace(srv.URL, "http://", "http://user:secret@", 1)
+	if _, _, err := localInput(u + "/missing.json"); err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("missing input: err = %v", err)
+	}
+}
+
+func TestLocalInputKeepsPaths(t *testing.T) {
+	path, cleanup, err := localInput("examples/openapi.yaml")
+	if err != nil || path != "examples/openapi.yaml" {
+		t.Errorf("localInput = %q, %v", path, err)
+	}
+	cleanup()
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import "strings"
+
+// filterByMethods keeps only requests whose HTTP method is listed (case-insensitive).
+// An empty list keeps everything. It returns the kept requests and how many were dropped.
+func filterByMethods(requests []APIRequest, methods []string) ([]APIRequest, int) {
+	if len(methods) == 0 {
+		return requests, 0
+	}
+
+	allowed := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		if m = strings.ToUpper(strings.TrimSpace(m)); m != "" {
+			allowed[m] = true
+		}
+	}
+
+	kept := make([]APIRequest, 0, len(requests))
+	for _, req := range requests {
+		if allowed[strings.ToUpper(req.Method)] {
+			kept = append(kept, req)
+		}
+	}
+
+	return kept, len(requests) - len(kept)
+}
+
+// filterByEndpoint keeps requests matching spec, either "METHOD path" or a
+// bare substring of the URL (both case-insensitive). An empty spec keeps
+// everything.
+func filterByEndpoint(requests []APIRequest, spec string) []APIRequest {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return requests
+	}
+
+	method, path := "", spec
+	if m, rest, ok := strings.Cut(spec, " "); ok && isHTTPMethod(strings.ToUpper(m)) {
+		method, path = strings.ToUpper(m), strings.TrimSpace(rest)
+	}
+	path = strings.ToLower(path)
+
+	kept := []APIRequest{}
+	for _, req := range requests {
+		if method != "" && strings.ToUpper(req.Method) != method {
+			continue
+		}
+		if strings.Contains(strings.ToLower(req.URL), path) {
+			kept = append(kept, req)
+		}
+	}
+	return kept
+}
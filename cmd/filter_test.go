@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// requestLines lists each request as "METHOD url", in order
+func requestLines(requests []APIRequest) []string {
+	methods := make([]string, len(requests))
+	for i, req := range requests {
+		methods[i] = req.Method + " " + req.URL
+	}
+	return methods
+}
+
+func TestFilterByMethods(t *testing.T) {
+	requests := []APIRequest{
+		{Method: "GET", URL: "/a"},
+		{Method: "post", URL: "/b"},
+		{Method: "DELETE", URL: "/c"},
+		{Method: "GET", URL: "/d"},
+	}
+
+	kept, dropped := filterByMethods(requests, []string{" get", "POST", ""})
+	if got := requestLines(kept); len(got) != 3 || got[0] != "GET /a" || got[1] != "post /b" || got[2] != "GET /d" || dropped != 1 {
+		t.Errorf("filterByMethods = %q, dropped %d", got, dropped)
+	}
+
+	if kept, dropped := filterByMethods(requests, nil); len(kept) != len(requests) || dropped != 0 {
+		t.Errorf("an empty filter kept %d, dropped %d", len(kept), dropped)
+	}
+}
+
+func TestFilterByEndpoint(t *testing.T) {
+	requests := []APIRequest{
+		{Method: "GET", URL: "https://api.example.com/api/users/{user_id}"},
+		{Method: "DELETE", URL: "https://api.example.com/api/users/{user_id}"},
+		{Method: "GET", URL: "https://api.example.com/api/Orders/{order_id}"},
+	}
+
+	for _, tc := range []struct {
+		spec string
+		want []string
+	}{
+		{"delete /api/users/", []string{"DELETE https://api.example.com/api/users/{user_id}"}},
+		{"/api/orders", []string{"GET https://api.example.com/api/Orders/{order_id}"}},
+		{"users", []string{"GET https://api.example.com/api/users/{user_id}", "DELETE https://api.example.com/api/users/{user_id}"}},
+		// Not a method: the whole spec is a URL substring
+		{"FETCH /api/users", []string{}},
+		{"  ", requestLines(requests)},
+	} {
+		got := requestLines(filterByEndpoint(requests, tc.spec))
+		if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+			t.Errorf("filterByEndpoint(%q) = %q, want %q", tc.spec, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// parseHeaderFlags turns repeated "Key: Value" strings into a header set
+func parseHeaderFlags(values []string) (http.Header, error) {
+	headers := make(http.Header)
+	for _, v := range values {
+		i := strings.Index(v, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid header %q (expected \"Key: Value\")", v)
+		}
+		key := strings.TrimSpace(v[:i])
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("invalid header name in %q", v)
+		}
+		headers.Add(key, strings.TrimSpace(v[i+1:]))
+	}
+	return headers, nil
+}
+
+// SetGlobalHeaders sets headers sent on every request, including
+// unauthenticated ones. A user's own headers take precedence; captured
+// request headers do not.
+func (s *Scanner) SetGlobalHeaders(h http.Header) {
+	s.globalHeaders = h.Clone()
+}
+
+// SetGatewayBasicAuth sends HTTP basic credentials for an edge gateway in
+// front of the app. They belong to the environment, not to a user, so the
+// no-auth test keeps them while stripping app credentials. Users whose app
+// auth also uses Authorization replace them.
+func (s *Scanner) SetGatewayBasicAuth(user, pass string) {
+	if user == "" && pass == "" {
+		s.gatewayAuth = ""
+		return
+	}
+	s.gatewayAuth = "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+}
+
+// authorizationUsers names the users whose app auth is sent in the
+// Authorization header and would therefore displace gateway basic auth
+func authorizationUsers(users []User) []string {
+	names := []string{}
+	for _, u := range users {
+		uses := u.OAuth != nil
+		for _, set := range credentialContexts(u) {
+			for key := range set.Headers {
+				if http.CanonicalHeaderKey(key) == "Authorization" {
+					uses = true
+				}
+			}
+		}
+		if uses {
+			names = append(names, u.Name)
+		}
+	}
+	return names
+}
+
+// applyGlobalHeaders sets the global headers and gateway auth on httpReq,
+// skipping any the user defines
+func (s *Scanner) applyGlobalHeaders(httpReq *http.Request, userHeaders map[string]string) {
+	if len(s.globalHeaders) == 0 && s.gatewayAuth == "" {
+		return
+	}
+
+	overridden := make(map[string]bool, len(userHeaders))
+	for key := range userHeaders {
+		overridden[http.CanonicalHeaderKey(key)] = true
+	}
+
+	for key, vals := range s.globalHeaders {
+		if overridden[http.CanonicalHeaderKey(key)] {
+			continue
+		}
+		httpReq.Header.Del(key)
+		for _, val := range vals {
+			httpReq.Header.Add(key, val)
+		}
+	}
+
+	if s.gatewayAuth != "" && !overridden["Authorization"] {
+		httpReq.Header.Set("Authorization", s.gatewayAuth)
+	}
+}
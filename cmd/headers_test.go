@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestParseHeaderFlags(t *testing.T) {
+	h, err := parseHeaderFlags([]string{"X-Env: staging", "x-trace:  a:b ", "X-Env: canary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Values("X-Env"); len(got) != 2 || got[0] != "staging" || got[1] != "canary" {
+		t.Errorf("X-Env = %q", got)
+	}
+	if got := h.Get("X-Trace"); got != "a:b" {
+		t.Errorf("X-Trace = %q", got)
+	}
+
+	for _, bad := range []string{"no colon", ": empty name", "Bad Name: x"} {
+		if _, err := parseHeaderFlags([]string{bad}); err == nil {
+			t.Errorf("parseHeaderFlags(%q) succeeded", bad)
+		}
+	}
+}
+
+// --header goes on every request, unauthenticated ones too, replacing the
+// captured value; a user's own header wins
+func TestGlobalHeaders(t *testing.T) {
+	srv := newRecordingServer(t, selftestHandler())
+	req := getRequest(srv.URL + "/api/users/{user_id}")
+	req.Headers.Set("X-Env", "recorded")
+	users := selftestUsers()
+	users[1].Headers["X-Env"] = "bob-env"
+
+	s := fastScanner(users, []APIRequest{req})
+	s.SetGlobalHeaders(http.Header{"X-Env": {"staging"}})
+	if _, err := s.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	noAuth := 0
+	for _, r := range srv.requests() {
+		want := "staging"
+		switch r.Header.Get("Authorization") {
+		case "Bearer bob-token":
+			want = "bob-env"
+		case "":
+			noAuth++
+		}
+		if got := r.Header.Values("X-Env"); len(got) != 1 || got[0] != want {
+			t.Errorf("%s %s as %q sent X-Env %q, want %q", r.Method, r.Path, r.Header.Get("Authorization"), got, want)
+		}
+	}
+	if noAuth == 0 {
+		t.Error("no unauthenticated request sent")
+	}
+}
+
+// Gateway basic auth rides on every request, the no-auth test included, so
+// scans reach the app behind it
+func TestGatewayBasicAuth(t *testing.T) {
+	inner := selftestHandler()
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "edge" || pass != "gate" {
+			http.Error(w, "gateway login required", http.StatusUnauthorized)
+			return
+		}
+		// The app behind the gateway takes its own key
+		r.Header.Set("Authorization", r.Header.Get("X-Api-Key"))
+		inner.ServeHTTP(w, r)
+	}))
+	users := []User{
+		{Name: "alice", Headers: map[string]string{"X-Api-Key": "alice-key"}, Params: map[string]string{"user_id": "123"}},
+		{Name: "bob", Headers: map[string]string{"X-Api-Key": "bob-key"}, Params: map[string]string{"user_id": "456"}},
+	}
+	s := fastScanner(users, []APIRequest{getRequest(srv.URL + "/api/users/{user_id}")})
+	s.SetGatewayBasicAuth("edge", "gate")
+
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !crossUserOn(findings, srv.URL+"/api/users/{user_id}") {
+		t.Errorf("IDOR behind the gateway not found: %+v", findings)
+	}
+	for _, r := range srv.requests() {
+		if _, _, ok := (&http.Request{Header: r.Header}).BasicAuth(); !ok {
+			t.Errorf("%s %s went without gateway auth", r.Method, r.Path)
+		}
+	}
+}
+
+func TestAuthorizationUsers(t *testing.T) {
+	users := []User{
+		{Name: "alice", Headers: map[string]string{"authorization": "Bearer a"}},
+		{Name: "bob", Headers: map[string]string{"X-Api-Key": "b"}},
+		{Name: "carol", Credentials: []CredentialSet{{Headers: map[string]string{"X-Api-Key": "c"}}, {Headers: map[string]string{"Authorization": "Bearer c"}}}},
+		{Name: "svc", OAuth: &OAuthConfig{TokenEndpoint: "https://auth.example.com/token", ClientID: "svc"}},
+	}
+	if got := authorizationUsers(users); len(got) != 3 || got[0] != "alice" || got[1] != "carol" || got[2] != "svc" {
+		t.Errorf("authorizationUsers = %q, want alice, carol and svc", got)
+	}
+}
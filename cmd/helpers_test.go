@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/itxdeeni/idor-scan/internal/testserver"
+)
+
+// seenRequest is one request a recordingServer received
+type seenRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+}
+
+// recordingServer serves handler and keeps every request it receives
+type recordingServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	seen []seenRequest
+}
+
+func newRecordingServer(t *testing.T, handler http.Handler) *recordingServer {
+	t.Helper()
+	rs := &recordingServer{}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rs.mu.Lock()
+		rs.seen = append(rs.seen, seenRequest{r.Method, r.URL.Path, r.URL.RawQuery, r.Header.Clone()})
+		rs.mu.Unlock()
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+// requests returns what the server has received so far
+func (rs *recordingServer) requests() []seenRequest {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return append([]seenRequest(nil), rs.seen...)
+}
+
+// selftestHandler is the demo API the selftest command scans
+func selftestHandler() http.Handler {
+	return testserver.Handler()
+}
+
+// selftestUsers are the two users selftestHandler knows
+func selftestUsers() []User {
+	return []User{
+		{Name: "alice", Headers: map[string]string{"Authorization": "Bearer alice-token"}, Params: map[string]string{"user_id": "123"}},
+		{Name: "bob", Headers: map[string]string{"Authorization": "Bearer bob-token"}, Params: map[string]string{"user_id": "456"}},
+	}
+}
+
+// getRequest is a GET template for url
+func getRequest(url string) APIRequest {
+	return APIRequest{Method: "GET", URL: url, Headers: make(http.Header), Params: make(map[string]string)}
+}
+
+// fastScanner builds a scanner that doesn't wait between requests
+func fastScanner(users []User, requests []APIRequest) *Scanner {
+	s := NewScanner(users, requests)
+	s.rateDelay = 0
+	return s
+}
+
+// recordingLogger keeps warnings for assertions and drops debug output
+type recordingLogger struct {
+	mu    sync.Mutex
+	warns []string
+}
+
+func (l *recordingLogger) Debugf(format string, args ...any) {}
+
+func (l *recordingLogger) Warnf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns = append(l.warns, fmt.Sprintf(format, args...))
+}
+
+// warnings returns the warnings logged so far
+func (l *recordingLogger) warnings() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.warns...)
+}
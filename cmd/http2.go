@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// SetHTTP2 makes the scanner speak HTTP/2. With force, HTTPS requests that
+// negotiate anything else fail instead of silently falling back to
+// HTTP/1.1. With h2c, http:// targets get cleartext HTTP/2 with prior
+// knowledge, tunnelled with CONNECT through the proxy when one is set.
+func (s *Scanner) SetHTTP2(force, h2c bool) {
+	t := s.transport()
+	t.ForceAttemptHTTP2 = true
+	s.forceHTTP2 = force
+
+	if h2c && !s.h2c {
+		t.RegisterProtocol("http", h2cTransport(t))
+		s.h2c = true
+	}
+}
+
+// checkProtocol rejects a response that didn't come back over HTTP/2 when
+// SetHTTP2 forced it
+func (s *Scanner) checkProtocol(req *http.Request, resp *http.Response) error {
+	if !s.forceHTTP2 || resp.ProtoMajor == 2 {
+		return nil
+	}
+	hint := ""
+	if req.URL.Scheme == "http" && !s.h2c {
+		hint = " (use --h2c for cleartext targets)"
+	}
+	return fmt.Errorf("%s answered over %s, not HTTP/2%s", req.URL.Host, resp.Proto, hint)
+}
+
+// noteProtocol remembers the protocol each host answered over
+func (s *Scanner) noteProtocol(req *http.Request, resp *http.Response) {
+	s.protocols.Store(req.URL.Host, resp.Proto)
+}
+
+// protocolFor returns the protocol the finding's host last answered over,
+// or "" when it is plain HTTP/1.1 or unknown
+func (s *Scanner) protocolFor(f Finding) string {
+	u, err := url.Parse(f.Endpoint)
+	if err != nil {
+		return ""
+	}
+	proto, ok := s.protocols.Load(u.Host)
+	if !ok || proto == "HTTP/1.1" {
+		return ""
+	}
+	return proto.(string)
+}
+
+// h2cTransport speaks HTTP/2 with prior knowledge over plain TCP, resolving
+// the proxy from t on every dial so later SetProxy calls still apply
+func h2cTransport(t *http.Transport) *http2.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+	return &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var proxy *url.URL
+			if t.Proxy != nil {
+				var err error
+				proxy, err = t.Proxy(&http.Request{URL: &url.URL{Scheme: "http", Host: addr}})
+				if err != nil {
+					return nil, err
+				}
+			}
+			if proxy == nil {
+				return dialer.DialContext(ctx, network, addr)
+			}
+			return dialTunnel(ctx, dialer, proxy, addr)
+		},
+	}
+}
+
+// dialTunnel opens a CONNECT tunnel to addr through an HTTP proxy. A plain
+// forwarding proxy would downgrade cleartext HTTP/2, so h2c needs the tunnel.
+func dialTunnel(ctx context.Context, dialer *net.Dialer, proxy *url.URL, addr string) (net.Conn, error) {
+	if proxy.Scheme != "http" {
+		return nil, fmt.Errorf("h2c needs an http:// proxy, got %s", proxy.Scheme)
+	}
+	proxyAddr := proxy.Host
+	if proxy.Port() == "" {
+		proxyAddr = net.JoinHostPort(proxy.Hostname(), "80")
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	connect := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if proxy.User != nil {
+		pass, _ := proxy.User.Password()
+		creds := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + pass))
+		connect.Header.Set("Proxy-Authorization", "Basic "+creds)
+	}
+	if err := connect.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, connect)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused CONNECT to %s: %s", addr, resp.Status)
+	}
+
+	if br.Buffered() > 0 {
+		return bufferedConn{conn, br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn reads through the bufio.Reader that consumed the CONNECT
+// response, so bytes the server sent early are not lost
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/http2"
+	h2cserver "golang.org/x/net/http2/h2c"
+)
+
+// protoServer serves the demo API over cleartext HTTP/1.1 and, when h2 is
+// set, h2c, recording the protocol of every request
+func protoServer(t *testing.T, h2 bool) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	protos := []string{}
+	inner := selftestHandler()
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		protos = append(protos, r.Proto)
+		mu.Unlock()
+		inner.ServeHTTP(w, r)
+	})
+	if h2 {
+		handler = h2cserver.NewHandler(handler, &http2.Server{})
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), protos...)
+	}
+}
+
+// --h2c speaks cleartext HTTP/2 and findings note the protocol
+func TestH2C(t *testing.T) {
+	srv, protos := protoServer(t, true)
+	s := fastScanner(selftestUsers(), []APIRequest{getRequest(srv.URL + "/api/users/{user_id}")})
+	s.SetHTTP2(true, true)
+
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range protos() {
+		if p != "HTTP/2.0" {
+			t.Errorf("server got a %s request", p)
+		}
+	}
+	if len(findings) == 0 || !strings.Contains(findings[0].Evidence, "Protocol: HTTP/2.0") {
+		t.Errorf("findings = %+v, want the HTTP/2 IDOR noted", findings)
+	}
+}
+
+// --http2 without --h2c refuses an HTTP/1.1 answer instead of testing over it
+func TestForcedHTTP2RejectsHTTP1(t *testing.T) {
+	srv, _ := protoServer(t, false)
+	s := fastScanner(selftestUsers(), nil)
+	s.SetHTTP2(true, false)
+
+	resp, err := s.executeRequest(s.buildRequest(getRequest(srv.URL+"/health"), User{}, nil))
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("HTTP/1.1 response accepted")
+	}
+	if !strings.Contains(err.Error(), "not HTTP/2 (use --h2c for cleartext targets)") {
+		t.Errorf("error = %v", err)
+	}
+}
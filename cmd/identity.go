@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+)
+
+// sharedIdentityWarnings reports pairs of users that carry the same
+// credentials or the same params. Cross-user tests between such users compare
+// a user with themselves, so every "finding" between them would be false.
+func sharedIdentityWarnings(users []User) []string {
+	warnings := []string{}
+
+	for i := 0; i < len(users); i++ {
+		a := users[i]
+		if a.IsAnonymous() {
+			continue
+		}
+		for j := i + 1; j < len(users); j++ {
+			b := users[j]
+			if b.IsAnonymous() {
+				continue
+			}
+
+			if shared, sets := sharedCredentials(a, b); shared {
+				detail := ""
+				if len(sets) > 0 {
+					detail = " (" + strings.Join(sets, ", ") + ")"
+				}
+				warnings = append(warnings, fmt.Sprintf("users '%s' and '%s' share identical credentials%s; tests between them are meaningless",
+					a.Name, b.Name, detail))
+			}
+			if len(a.Params) > 0 && sameParams(a.Params, b.Params) {
+				warnings = append(warnings, fmt.Sprintf("users '%s' and '%s' have identical params; their resource IDs can't be told apart",
+					a.Name, b.Name))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// sharedCredentials reports whether a and b authenticate the same way in any
+// of their credential sets, naming the matching sets ("alice web = bob #2")
+// when the users have more than one
+func sharedCredentials(a, b User) (bool, []string) {
+	theirs := make(map[string]string)
+	for _, set := range credentialContexts(b) {
+		if fp := credentialFingerprint(b, set.Headers); fp != "" {
+			theirs[fp] = set.Label
+		}
+	}
+
+	shared := false
+	sets := []string{}
+	for _, set := range credentialContexts(a) {
+		label, ok := theirs[credentialFingerprint(a, set.Headers)]
+		if !ok {
+			continue
+		}
+		shared = true
+		if set.Label != "" || label != "" {
+			sets = append(sets, fmt.Sprintf("%s = %s", setName(a, set.Label), setName(b, label)))
+		}
+	}
+	return shared, sets
+}
+
+// credentialContexts lists the header sets a user authenticates with. The
+// primary headers carry an empty label and, when a user sets both headers and
+// credentials, come first unless they repeat the first credential set.
+func credentialContexts(u User) []CredentialSet {
+	if len(u.Credentials) == 0 {
+		return []CredentialSet{{Headers: u.Headers}}
+	}
+	sets := make([]CredentialSet, 0, len(u.Credentials)+1)
+	if len(u.Headers) > 0 && !maps.Equal(u.Headers, u.Credentials[0].Headers) {
+		sets = append(sets, CredentialSet{Headers: u.Headers})
+	}
+	for i, set := range u.Credentials {
+		if set.Label == "" {
+			set.Label = fmt.Sprintf("#%d", i+1)
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+func setName(u User, label string) string {
+	if label == "" {
+		return u.Name
+	}
+	return u.Name + " " + label
+}
+
+// credentialFingerprint reduces what a user authenticates with to a
+// comparable string. Only auth-looking headers count, so a shared Accept
+// doesn't make two users look alike; if none look like auth, all headers do.
+func credentialFingerprint(u User, headers map[string]string) string {
+	parts := []string{}
+	for key, val := range headers {
+		if isAuthName(key) {
+			parts = append(parts, "h:"+strings.ToLower(key)+"="+val)
+		}
+	}
+	if len(parts) == 0 {
+		for key, val := range headers {
+			parts = append(parts, "h:"+strings.ToLower(key)+"="+val)
+		}
+	}
+	for key, val := range u.AuthParams {
+		parts = append(parts, "q:"+key+"="+val)
+	}
+	for key, val := range u.Cookies {
+		parts = append(parts, "c:"+key+"="+val)
+	}
+	if u.OAuth != nil {
+		parts = append(parts, "o:"+u.OAuth.TokenEndpoint+"|"+u.OAuth.ClientID+"|"+u.OAuth.Scope)
+	}
+
+	sort.Strings(parts)
+	return strings.Join(parts, "\n")
+}
+
+func sameParams(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, val := range a {
+		if other, ok := b[key]; !ok || other != val {
+			return false
+		}
+	}
+	return true
+}
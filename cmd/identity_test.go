@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSharedIdentityWarnings(t *testing.T) {
+	bearer := func(token string) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + token, "Accept": "application/json"}
+	}
+	for _, tc := range []struct {
+		name  string
+		users []User
+		want  []string
+	}{
+		{"distinct", []User{
+			{Name: "alice", Headers: bearer("a"), Params: map[string]string{"user_id": "1"}},
+			{Name: "bob", Headers: bearer("b"), Params: map[string]string{"user_id": "2"}},
+		}, nil},
+		{"same token, other headers", []User{
+			{Name: "alice", Headers: bearer("a")},
+			{Name: "bob", Headers: map[string]string{"Authorization": "Bearer a", "Accept": "text/plain"}},
+		}, []string{"users 'alice' and 'bob' share identical credentials; tests between them are meaningless"}},
+		{"same params", []User{
+			{Name: "alice", Headers: bearer("a"), Params: map[string]string{"user_id": "1"}},
+			{Name: "bob", Headers: bearer("b"), Params: map[string]string{"user_id": "1"}},
+		}, []string{"users 'alice' and 'bob' have identical params; their resource IDs can't be told apart"}},
+		{"shared credential set", []User{
+			{Name: "alice", Credentials: []CredentialSet{{Label: "web", Headers: bearer("a")}, {Label: "mobile", Headers: bearer("m")}}},
+			{Name: "bob", Credentials: []CredentialSet{{Headers: bearer("b")}, {Headers: bearer("m")}}},
+		}, []string{"users 'alice' and 'bob' share identical credentials (alice mobile = bob #2); tests between them are meaningless"}},
+		{"guests are alike by design", []User{{Name: "guest"}, {Name: "anon"}}, nil},
+	} {
+		got := sharedIdentityWarnings(tc.users)
+		if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+			t.Errorf("%s: warnings = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -10,13 +13,82 @@ type IDPattern struct {
 	Location string // "path", "query", "body"
 	Key      string // parameter name or path segment index
 	Value    string // the actual ID value
+	Kind     string // "uuid", "objectid", "numeric" or "placeholder"
 }
 
-// Common ID patterns
-var idPatterns = []*regexp.Regexp{
-	regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`), // UUID
-	regexp.MustCompile(`[0-9a-f]{24}`),                                                   // MongoDB ObjectId
-	regexp.MustCompile(`\b\d{1,10}\b`),                                                   // Numeric ID (1-10 digits)
+// Common ID patterns (anchored: the whole path segment must be the ID)
+var idPatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"uuid", regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)},
+	{"objectid", regexp.MustCompile(`^[0-9a-f]{24}$`)}, // MongoDB ObjectId
+	{"numeric", regexp.MustCompile(`^\d{1,10}$`)},      // 1-10 digits
+}
+
+// Path segments whose following segment is never an object ID (/api/2/, /v/3/, /version/1/)
+var nonIDSegments = map[string]bool{
+	"v": true, "api": true, "version": true, "versions": true,
+}
+
+// isNonIDSegment reports whether the segment after this one should never be treated as an ID
+func isNonIDSegment(seg string) bool {
+	return nonIDSegments[strings.ToLower(seg)]
+}
+
+// versionSegment matches API version segments such as v1 or v2beta
+var versionSegment = regexp.MustCompile(`(?i)^v\d+[a-z]*\d*$`)
+
+// isIDShaped reports whether a path segment looks like an object ID on its own
+func isIDShaped(seg string) bool {
+	for _, p := range idPatterns {
+		if p.re.MatchString(seg) {
+			return true
+		}
+	}
+	return false
+}
+
+// idPositions marks which path segments sit where an object ID would.
+// Version prefixes (/api/, /v1/, /version/2/) are skipped; after them
+// segments alternate between resource names and IDs (/users/alice/orders/7),
+// except that an ID-shaped segment is always an ID (/orders/7/8).
+func idPositions(segments []string) []bool {
+	ids := make([]bool, len(segments))
+	expectResource := true
+	for i, seg := range segments {
+		switch {
+		case seg == "":
+		case isNonIDSegment(seg) || versionSegment.MatchString(seg) || i > 0 && isNonIDSegment(segments[i-1]):
+			expectResource = true
+		case isIDShaped(seg) || !expectResource:
+			ids[i] = true
+			expectResource = true
+		default:
+			expectResource = false
+		}
+	}
+	return ids
+}
+
+// splitURL separates a URL into scheme+host (including any port), path, and query/fragment
+func splitURL(urlStr string) (prefix, path, suffix string) {
+	if i := strings.IndexAny(urlStr, "?#"); i >= 0 {
+		urlStr, suffix = urlStr[:i], urlStr[i:]
+	}
+
+	path = urlStr
+	if i := strings.Index(urlStr, "://"); i >= 0 {
+		rest := urlStr[i+3:]
+		slash := strings.Index(rest, "/")
+		if slash < 0 {
+			return urlStr, "", suffix
+		}
+		prefix = urlStr[:i+3+slash]
+		path = rest[slash:]
+	}
+
+	return prefix, path, suffix
 }
 
 // Path segments that typically contain IDs
@@ -28,29 +100,37 @@ var idSegmentPatterns = []string{
 	"customers", "customer", "products", "product", "invoices", "invoice",
 }
 
-// ExtractIDsFromURL finds potential ID values in a URL path
+// ExtractIDsFromURL finds potential ID values in a URL path.
+// The host (and port) and query string are ignored, as is any segment that
+// follows a version keyword such as /api/ or /v/.
 func ExtractIDsFromURL(urlStr string) []IDPattern {
 	patterns := []IDPattern{}
 
 	// Parse path segments
-	parts := strings.Split(urlStr, "/")
+	_, path, _ := splitURL(urlStr)
+	parts := strings.Split(path, "/")
 	for i, part := range parts {
 		if part == "" {
 			continue
 		}
 
+		if i > 0 && isNonIDSegment(parts[i-1]) {
+			continue
+		}
+
 		// Check if previous segment suggests this is an ID
 		if i > 0 {
 			prevPart := strings.ToLower(parts[i-1])
 			for _, seg := range idSegmentPatterns {
 				if prevPart == seg || strings.HasSuffix(prevPart, seg) {
 					// This segment likely contains an ID
-					for _, re := range idPatterns {
-						if re.MatchString(part) {
+					for _, p := range idPatterns {
+						if p.re.MatchString(part) {
 							patterns = append(patterns, IDPattern{
 								Location: "path",
 								Key:      prevPart,
 								Value:    part,
+								Kind:     p.kind,
 							})
 							break
 						}
@@ -66,6 +146,7 @@ func ExtractIDsFromURL(urlStr string) []IDPattern {
 				Location: "path",
 				Key:      strings.Trim(part, "{}"),
 				Value:    part,
+				Kind:     "placeholder",
 			})
 		}
 	}
@@ -112,12 +193,19 @@ func BuildSwappedURL(originalURL string, attackerParams, victimParams map[string
 	// This handles cases where the URL already has the attacker's ID baked in
 	for key, attackerVal := range attackerParams {
 		if victimVal, ok := victimParams[key]; ok && attackerVal != victimVal {
-			// Replace attacker's ID with victim's ID
-			result = strings.ReplaceAll(result, "/"+attackerVal+"/", "/"+victimVal+"/")
-			result = strings.ReplaceAll(result, "/"+attackerVal+"?", "/"+victimVal+"?")
-			if strings.HasSuffix(result, "/"+attackerVal) {
-				result = strings.TrimSuffix(result, "/"+attackerVal) + "/" + victimVal
+			// Replace attacker's ID with victim's ID, path segments only
+			// (never the host/port, a version segment like /api/1/, or a
+			// resource name)
+			prefix, path, suffix := splitURL(result)
+			segments := strings.Split(path, "/")
+			ids := idPositions(segments)
+			for i, seg := range segments {
+				if seg == attackerVal && ids[i] {
+					segments[i] = victimVal
+				}
 			}
+			result = prefix + strings.Join(segments, "/") + suffix
+
 			// Also handle query params
 			result = strings.ReplaceAll(result, "="+attackerVal+"&", "="+victimVal+"&")
 			if strings.HasSuffix(result, "="+attackerVal) {
@@ -129,8 +217,16 @@ func BuildSwappedURL(originalURL string, attackerParams, victimParams map[string
 	return result
 }
 
-// BuildSwappedBody replaces IDs in request body
+// BuildSwappedBody replaces IDs in request body, leaving card numbers alone
 func BuildSwappedBody(originalBody string, attackerParams, victimParams map[string]string) string {
+	return swapBodyIDs(originalBody, attackerParams, victimParams, false)
+}
+
+// swapBodyIDs is BuildSwappedBody with the card-number guard optional.
+// Unless allowCards is set, an attacker value that passes the Luhn check is
+// never replaced, and neither is a shorter ID inside such a digit run: a
+// corrupted card number can fail a payment or trip fraud checks on the target.
+func swapBodyIDs(originalBody string, attackerParams, victimParams map[string]string, allowCards bool) string {
 	result := originalBody
 
 	// Replace placeholders
@@ -148,12 +244,275 @@ func BuildSwappedBody(originalBody string, attackerParams, victimParams map[stri
 	// Replace attacker's values with victim's values
 	for key, attackerVal := range attackerParams {
 		if victimVal, ok := victimParams[key]; ok && attackerVal != victimVal {
-			// JSON: "user_id": "123" -> "user_id": "456"
-			result = strings.ReplaceAll(result, `"`+attackerVal+`"`, `"`+victimVal+`"`)
-			// Also plain value replacement
-			result = strings.ReplaceAll(result, attackerVal, victimVal)
+			if allowCards {
+				// JSON: "user_id": "123" -> "user_id": "456"
+				result = strings.ReplaceAll(result, `"`+attackerVal+`"`, `"`+victimVal+`"`)
+				// Also plain value replacement
+				result = strings.ReplaceAll(result, attackerVal, victimVal)
+				continue
+			}
+			if looksLikeCardNumber(attackerVal) {
+				continue
+			}
+			result = replaceOutsideCards(result, attackerVal, victimVal)
 		}
 	}
 
 	return result
 }
+
+// looksLikeCardNumber reports whether v is 13-19 digits passing the Luhn
+// check, the shape of a payment card number
+func looksLikeCardNumber(v string) bool {
+	if len(v) < 13 || len(v) > 19 || !isNumericID(v) {
+		return false
+	}
+	sum := 0
+	for i := len(v) - 1; i >= 0; i-- {
+		d := int(v[i] - '0')
+		if (len(v)-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// replaceOutsideCards replaces every old with new except where old is part of
+// a run of digits that looks like a card number
+func replaceOutsideCards(s, old, new string) string {
+	if old == "" {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for pos := 0; ; {
+		i := strings.Index(s[pos:], old)
+		if i < 0 {
+			break
+		}
+		i += pos
+		pos = i + len(old)
+
+		start, end := i, pos
+		for start > 0 && isDigit(s[start-1]) {
+			start--
+		}
+		for end < len(s) && isDigit(s[end]) {
+			end++
+		}
+		if looksLikeCardNumber(s[start:end]) {
+			continue
+		}
+		b.WriteString(s[last:i])
+		b.WriteString(new)
+		last = pos
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// IDLocation pins where an endpoint's object ID lives, overriding the heuristics.
+// Configured under `id_locations` in the config file.
+type IDLocation struct {
+	Pattern   string `mapstructure:"pattern"`    // substring of the request URL
+	Method    string `mapstructure:"method"`     // optional HTTP method filter
+	Param     string `mapstructure:"param"`      // user param that supplies the ID
+	JSONPath  string `mapstructure:"json_path"`  // body location, e.g. $.order.owner.id
+	PathIndex *int   `mapstructure:"path_index"` // URL path segment (0 = first segment after host)
+}
+
+// Matches reports whether the location applies to the given request
+func (l IDLocation) Matches(req APIRequest) bool {
+	if l.Method != "" && !strings.EqualFold(l.Method, req.Method) {
+		return false
+	}
+	return strings.Contains(req.URL, l.Pattern)
+}
+
+// SetPathSegment replaces the path segment at index (0 = first segment after
+// the host). It fails when the URL's path has no segment at index.
+func SetPathSegment(urlStr string, index int, value string) (string, error) {
+	prefix, path, query := splitURL(urlStr)
+	segments := []string{}
+	if path != "" {
+		segments = strings.Split(strings.TrimPrefix(path, "/"), "/")
+	}
+	if index < 0 || index >= len(segments) {
+		return "", fmt.Errorf("path_index %d is outside the %d segments of %q", index, len(segments), path)
+	}
+	segments[index] = value
+
+	return prefix + "/" + strings.Join(segments, "/") + query, nil
+}
+
+// SetJSONPath sets the value at a simple JSONPath ($.a.b[0].c) in a JSON body.
+// Numeric values are written as numbers when the existing value is a number.
+func SetJSONPath(body, path, value string) (string, error) {
+	tokens, err := parseJSONPath(path)
+	if err != nil {
+		return body, err
+	}
+
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	var root interface{}
+	if err := dec.Decode(&root); err != nil {
+		return body, fmt.Errorf("body is not JSON: %w", err)
+	}
+
+	var parent interface{}
+	var last interface{}
+	cur := root
+	for _, tok := range tokens {
+		parent, last = cur, tok
+		switch t := tok.(type) {
+		case string:
+			obj, ok := cur.(map[string]interface{})
+			if !ok {
+				return body, fmt.Errorf("%s: %q is not an object key", path, t)
+			}
+			cur = obj[t]
+		case int:
+			arr, ok := cur.([]interface{})
+			if !ok || t >= len(arr) {
+				return body, fmt.Errorf("%s: index %d out of range", path, t)
+			}
+			cur = arr[t]
+		}
+	}
+
+	var newVal interface{} = value
+	if _, isNum := cur.(json.Number); isNum && isNumericID(value) {
+		newVal = json.Number(value)
+	}
+
+	switch t := last.(type) {
+	case string:
+		parent.(map[string]interface{})[t] = newVal
+	case int:
+		parent.([]interface{})[t] = newVal
+	default:
+		return body, fmt.Errorf("%s: path must select a field", path)
+	}
+
+	out, err := json.Marshal(root)
+	if err != nil {
+		return body, err
+	}
+	return string(out), nil
+}
+
+// parseJSONPath splits $.a.b[0].c into ["a", "b", 0, "c"]
+func parseJSONPath(path string) ([]interface{}, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json_path %q must start with $", path)
+	}
+
+	tokens := []interface{}{}
+	for _, part := range strings.Split(strings.TrimPrefix(path, "$"), ".") {
+		if part == "" {
+			continue
+		}
+		for part != "" {
+			open := strings.Index(part, "[")
+			if open < 0 {
+				tokens = append(tokens, part)
+				break
+			}
+			if open > 0 {
+				tokens = append(tokens, part[:open])
+			}
+			end := strings.Index(part, "]")
+			if end < open {
+				return nil, fmt.Errorf("json_path %q has an unterminated index", path)
+			}
+			idx, err := strconv.Atoi(part[open+1 : end])
+			if err != nil {
+				return nil, fmt.Errorf("json_path %q has a non-numeric index", path)
+			}
+			tokens = append(tokens, idx)
+			part = part[end+1:]
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("json_path %q selects the whole document", path)
+	}
+	return tokens, nil
+}
+
+// SetIDLocations registers explicit ID locations that override detection heuristics
+func (s *Scanner) SetIDLocations(locs []IDLocation) error {
+	for _, loc := range locs {
+		if loc.Pattern == "" || loc.Param == "" {
+			return fmt.Errorf("id_locations entries need both pattern and param")
+		}
+		if loc.JSONPath == "" && loc.PathIndex == nil {
+			return fmt.Errorf("id_locations entry %q needs json_path or path_index", loc.Pattern)
+		}
+		if loc.PathIndex != nil && *loc.PathIndex < 0 {
+			return fmt.Errorf("id_locations entry %q: path_index must be 0 or more, got %d", loc.Pattern, *loc.PathIndex)
+		}
+		if loc.JSONPath != "" {
+			if _, err := parseJSONPath(loc.JSONPath); err != nil {
+				return err
+			}
+		}
+	}
+	s.idLocations = locs
+	return nil
+}
+
+// SetCardSwap lets body swaps replace values that look like card numbers,
+// which are left untouched by default
+func (s *Scanner) SetCardSwap(allowed bool) {
+	s.cardSwap = allowed
+}
+
+// idLocationFor returns the first configured location matching the request
+func (s *Scanner) idLocationFor(req APIRequest) *IDLocation {
+	for i := range s.idLocations {
+		if s.idLocations[i].Matches(req) {
+			return &s.idLocations[i]
+		}
+	}
+	return nil
+}
+
+// swapURL moves a request URL from one user's IDs to another's, honoring any
+// configured path location before falling back to BuildSwappedURL heuristics
+func (s *Scanner) swapURL(req APIRequest, fromParams, toParams map[string]string) string {
+	if loc := s.idLocationFor(req); loc != nil && loc.PathIndex != nil {
+		if val, ok := toParams[loc.Param]; ok {
+			url, err := SetPathSegment(req.URL, *loc.PathIndex, val)
+			if err == nil {
+				// Placeholders only; the pinned segment is authoritative
+				return BuildSwappedURL(url, nil, toParams)
+			}
+			s.log.Debugf("   ⚠️  id_locations %s: %v (falling back to heuristics)\n", loc.Pattern, err)
+		}
+	}
+	return BuildSwappedURL(req.URL, fromParams, toParams)
+}
+
+// swapBody is the body counterpart of swapURL, honoring a configured JSONPath
+func (s *Scanner) swapBody(req APIRequest, fromParams, toParams map[string]string) string {
+	if loc := s.idLocationFor(req); loc != nil && loc.JSONPath != "" {
+		if val, ok := toParams[loc.Param]; ok {
+			body, err := SetJSONPath(req.Body, loc.JSONPath, val)
+			if err == nil {
+				return swapBodyIDs(body, nil, toParams, s.cardSwap)
+			}
+			s.log.Debugf("   ⚠️  id_locations %s: %v (falling back to heuristics)\n", loc.JSONPath, err)
+		}
+	}
+	return swapBodyIDs(req.Body, fromParams, toParams, s.cardSwap)
+}
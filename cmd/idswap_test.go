@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractIDsFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want []IDPattern
+	}{
+		{"https://api.example.com/api/v1/users/123", []IDPattern{{"path", "users", "123", "numeric"}}},
+		{"http://localhost:8080/users/123/orders/456?page=2", []IDPattern{
+			{"path", "users", "123", "numeric"},
+			{"path", "orders", "456", "numeric"},
+		}},
+		{"/documents/507f1f77bcf86cd799439011", []IDPattern{{"path", "documents", "507f1f77bcf86cd799439011", "objectid"}}},
+		{"/accounts/3f2504e0-4f89-11d3-9a0c-0305e82c3301", []IDPattern{{"path", "accounts", "3f2504e0-4f89-11d3-9a0c-0305e82c3301", "uuid"}}},
+		{"/users/{user_id}/profile", []IDPattern{{"path", "user_id", "{user_id}", "placeholder"}}},
+		{"/api/2/users", []IDPattern{}},
+		{"/health", []IDPattern{}},
+	}
+	for _, tt := range tests {
+		if got := ExtractIDsFromURL(tt.url); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExtractIDsFromURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestBuildSwappedURL(t *testing.T) {
+	tests := []struct {
+		name             string
+		url              string
+		attacker, victim map[string]string
+		want             string
+	}{
+		{"id after resource", "/api/v1/users/123",
+			map[string]string{"user_id": "123"}, map[string]string{"user_id": "456"}, "/api/v1/users/456"},
+		{"version number left alone", "/api/1/users/1",
+			map[string]string{"user_id": "1"}, map[string]string{"user_id": "2"}, "/api/1/users/2"},
+		{"version segment left alone", "/api/v1/users/123",
+			map[string]string{"version": "v1"}, map[string]string{"version": "v2"}, "/api/v1/users/123"},
+		{"resource name left alone", "/api/v1/users/123",
+			map[string]string{"scope": "users"}, map[string]string{"scope": "admins"}, "/api/v1/users/123"},
+		{"name in id position", "/users/alice/orders",
+			map[string]string{"username": "alice", "kind": "orders"}, map[string]string{"username": "bob", "kind": "refunds"}, "/users/bob/orders"},
+		{"nested ids", "/orders/7/items/8",
+			map[string]string{"order": "7", "item": "8"}, map[string]string{"order": "70", "item": "80"}, "/orders/70/items/80"},
+		{"host and port untouched", "http://10.0.0.123:123/users/123",
+			map[string]string{"user_id": "123"}, map[string]string{"user_id": "456"}, "http://10.0.0.123:123/users/456"},
+		{"placeholder", "/users/{user_id}",
+			nil, map[string]string{"user_id": "456"}, "/users/456"},
+		{"query value", "/orders?owner=123&page=1",
+			map[string]string{"user_id": "123"}, map[string]string{"user_id": "456"}, "/orders?owner=456&page=1"},
+	}
+	for _, tt := range tests {
+		if got := BuildSwappedURL(tt.url, tt.attacker, tt.victim); got != tt.want {
+			t.Errorf("%s: BuildSwappedURL(%q) = %q, want %q", tt.name, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestSetIDLocationsRejectsNegativeIndex(t *testing.T) {
+	index := -1
+	err := NewScanner(nil, nil).SetIDLocations([]IDLocation{{Pattern: "/accounts", Param: "account_id", PathIndex: &index}})
+	if err == nil {
+		t.Error("a negative path_index was accepted")
+	}
+}
+
+// A path_index past the URL's last segment falls back to the heuristics
+// rather than sending the attacker's own request as the swap
+func TestSwapURLFallsBackFromShortPath(t *testing.T) {
+	index := 5
+	s := NewScanner(nil, nil)
+	if err := s.SetIDLocations([]IDLocation{{Pattern: "/accounts", Param: "account_id", PathIndex: &index}}); err != nil {
+		t.Fatal(err)
+	}
+	req := APIRequest{Method: "GET", URL: "https://api.example.com/accounts/111"}
+	got := s.swapURL(req, map[string]string{"account_id": "111"}, map[string]string{"account_id": "222"})
+	if want := "https://api.example.com/accounts/222"; got != want {
+		t.Errorf("swapURL = %q, want %q", got, want)
+	}
+
+	if _, err := SetPathSegment(req.URL, 5, "222"); err == nil {
+		t.Error("SetPathSegment accepted an index past the last segment")
+	}
+	if got, err := SetPathSegment(req.URL, 1, "222"); err != nil || got != "https://api.example.com/accounts/222" {
+		t.Errorf("SetPathSegment = %q, %v", got, err)
+	}
+}
+
+func TestLooksLikeCardNumber(t *testing.T) {
+	for v, want := range map[string]bool{
+		"4111111111111111": true,
+		"4111111111111112": false, // fails Luhn
+		"79927398713":      false, // Luhn-valid but too short
+		"12345":            false,
+		"4111-1111-1111":   false,
+	} {
+		if got := looksLikeCardNumber(v); got != want {
+			t.Errorf("looksLikeCardNumber(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+// body swaps leave card numbers alone, including an ID that happens to sit
+// inside one, unless --allow-card-swap is set
+func TestBodySwapSkipsCardNumbers(t *testing.T) {
+	body := `{"user_id":"123","card":"4111111111231117","note":"ref 123"}`
+	attacker := map[string]string{"user_id": "123", "card": "4111111111111111"}
+	victim := map[string]string{"user_id": "456", "card": "5500005555555559"}
+
+	guarded := `{"user_id":"456","card":"4111111111231117","note":"ref 456"}`
+	if got := BuildSwappedBody(body, attacker, victim); got != guarded {
+		t.Errorf("BuildSwappedBody =\n%s\nwant\n%s", got, guarded)
+	}
+	if got := BuildSwappedBody(`{"card":"4111111111111111"}`, attacker, victim); got != `{"card":"4111111111111111"}` {
+		t.Errorf("card-number param swapped: %s", got)
+	}
+
+	if got := swapBodyIDs(`{"card":"4111111111111111"}`, attacker, victim, true); got != `{"card":"5500005555555559"}` {
+		t.Errorf("allowCards: %s", got)
+	}
+}
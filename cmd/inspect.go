@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Show the IDs detected in each request and how they would be swapped",
+	Long: `Inspect prints, for every loaded request, the IDs detected in its URL, the
+request each user's baseline would send, and the URL and body generated for
+every attacker/victim pair. No requests are sent. Use it to see why a swap
+isn't reaching the victim's resource.`,
+	Run: runInspect,
+}
+
+func init() {
+	addInputFlags(inspectCmd)
+	rootCmd.AddCommand(inspectCmd)
+}
+
+func runInspect(cmd *cobra.Command, args []string) {
+	if !hasInputSource() {
+		fmt.Fprintln(os.Stderr, "Error: must specify at least one of --collection, --openapi, --har, or --requests-dir")
+		os.Exit(1)
+	}
+
+	users, err := loadUsers(usersFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading users: %v\n", err)
+		os.Exit(1)
+	}
+	requests, err := loadRequests()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading requests: %v\n", err)
+		os.Exit(1)
+	}
+
+	scanner := NewScanner(users, requests)
+	var idLocations []IDLocation
+	if err := viper.UnmarshalKey("id_locations", &idLocations); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading id_locations: %v\n", err)
+		os.Exit(1)
+	}
+	if err := scanner.SetIDLocations(idLocations); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	writeInspect(os.Stdout, scanner)
+}
+
+// writeInspect prints the inspect report for every request the scanner holds
+func writeInspect(w io.Writer, scanner *Scanner) {
+	pairs := scanner.testPairs()
+	for i, req := range scanner.Requests {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "🔎 %s %s\n", req.Method, req.URL)
+		if req.Body != "" {
+			fmt.Fprintf(w, "   Body: %s\n", req.Body)
+		}
+
+		ids := ExtractIDsFromURL(req.URL)
+		if len(ids) == 0 {
+			fmt.Fprintln(w, "   No IDs detected in the URL")
+		}
+		for _, id := range ids {
+			fmt.Fprintf(w, "   ID: %s %s=%s (%s)\n", id.Location, id.Key, id.Value, id.Kind)
+		}
+		if loc := scanner.idLocationFor(req); loc != nil {
+			where := loc.JSONPath
+			if loc.PathIndex != nil {
+				where = fmt.Sprintf("path segment %d", *loc.PathIndex)
+			}
+			fmt.Fprintf(w, "   id_locations: %s ← param %q\n", where, loc.Param)
+		}
+
+		fmt.Fprintln(w, "   Baselines:")
+		for _, user := range scanner.Users {
+			if user.IsAnonymous() {
+				continue
+			}
+			url, body := scanner.personalize(req, user)
+			fmt.Fprintf(w, "     %-12s %s\n", user.Name, url)
+			if body != "" {
+				fmt.Fprintf(w, "     %-12s %s\n", "", body)
+			}
+		}
+
+		fmt.Fprintln(w, "   Swaps:")
+		for _, pair := range pairs {
+			url, body := scanner.swappedRequest(req, pair.attacker, pair.victim)
+
+			note := ""
+			if scanner.swapsNothing(req, pair.attacker, pair.victim) {
+				note = "  ⚠️  nothing swapped: same as the attacker's own request"
+			}
+			fmt.Fprintf(w, "     %s → %s: %s%s\n", pair.attacker.Name, pair.victim.Name, url, note)
+			if body != "" {
+				fmt.Fprintf(w, "       Body: %s\n", body)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n📊 %d requests, %d users, %d attacker/victim pairs (nothing sent)\n",
+		len(scanner.Requests), len(scanner.Users), len(pairs))
+}
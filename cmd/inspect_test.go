@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// inspect shows each user's baseline and every swap, and flags pairs whose
+// swap leaves the attacker's own request unchanged
+func TestWriteInspect(t *testing.T) {
+	s := NewScanner(selftestUsers(), []APIRequest{
+		getRequest("https://api.example.com/api/users/{user_id}"),
+		getRequest("https://api.example.com/api/health"),
+	})
+
+	var out strings.Builder
+	writeInspect(&out, s)
+	report := out.String()
+
+	for _, want := range []string{
+		"ID: path user_id={user_id} (placeholder)",
+		"alice        https://api.example.com/api/users/123",
+		"alice → bob: https://api.example.com/api/users/456\n",
+		"No IDs detected in the URL",
+		"alice → bob: https://api.example.com/api/health  ⚠️  nothing swapped",
+		"2 requests, 2 users, 2 attacker/victim pairs (nothing sent)",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// envelopeKeys are tried in order when a list response wraps its items in an
+// object next to pagination metadata ({"data": [...], "total": 5, "page": 1})
+var envelopeKeys = []string{"data", "items", "results", "records", "entries"}
+
+// itemIDKeys identify an item inside a list response
+var itemIDKeys = []string{"id", "_id", "uuid"}
+
+// extractItemIDs returns the identifiers of the items in a JSON list response,
+// either a top-level array or one wrapped in a pagination envelope. The bool
+// reports whether the body was a list at all.
+func extractItemIDs(body []byte) ([]string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, false
+	}
+
+	items, ok := listItems(doc)
+	if !ok {
+		return nil, false
+	}
+
+	ids := []string{}
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range itemIDKeys {
+			if v, ok := obj[key]; ok && v != nil {
+				ids = append(ids, fmt.Sprint(v))
+				break
+			}
+		}
+	}
+	return ids, true
+}
+
+// listItems finds the item array in a decoded list response
+func listItems(doc interface{}) ([]interface{}, bool) {
+	switch node := doc.(type) {
+	case []interface{}:
+		return node, true
+	case map[string]interface{}:
+		for _, key := range envelopeKeys {
+			if items, ok := node[key].([]interface{}); ok {
+				return items, true
+			}
+		}
+
+		// Otherwise accept an envelope with exactly one array field
+		var found []interface{}
+		arrays := 0
+		for _, v := range node {
+			if items, ok := v.([]interface{}); ok {
+				found = items
+				arrays++
+			}
+		}
+		if arrays == 1 {
+			return found, true
+		}
+	}
+	return nil, false
+}
+
+// containedItems returns the victim's item IDs present in the attacker's
+// response, ignoring items the attacker also sees for their own resource
+func containedItems(victimIDs, ownIDs, gotIDs []string) []string {
+	own := make(map[string]bool, len(ownIDs))
+	for _, id := range ownIDs {
+		own[id] = true
+	}
+	got := make(map[string]bool, len(gotIDs))
+	for _, id := range gotIDs {
+		got[id] = true
+	}
+
+	leaked := []string{}
+	for _, id := range victimIDs {
+		if got[id] && !own[id] {
+			leaked = append(leaked, id)
+		}
+	}
+	sort.Strings(leaked)
+	return leaked
+}
+
+// summarizeIDs lists up to 10 IDs for evidence strings
+func summarizeIDs(ids []string) string {
+	if len(ids) <= 10 {
+		return strings.Join(ids, ", ")
+	}
+	return fmt.Sprintf("%s, ... (+%d more)", strings.Join(ids[:10], ", "), len(ids)-10)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExtractItemIDs(t *testing.T) {
+	for _, tc := range []struct {
+		body   string
+		want   []string
+		isList bool
+	}{
+		{`[{"id":1},{"id":"b"},{"name":"no id"}]`, []string{"1", "b"}, true},
+		{`{"data":[{"_id":"x1"}],"total":40,"page":2}`, []string{"x1"}, true},
+		{`{"orders":[{"uuid":"u1"},{"uuid":"u2"}],"next":"/page/3"}`, []string{"u1", "u2"}, true},
+		{`{"a":[1],"b":[2]}`, nil, false},
+		{`{"id":1,"name":"Alice"}`, nil, false},
+		{`not json`, nil, false},
+	} {
+		got, isList := extractItemIDs([]byte(tc.body))
+		if isList != tc.isList || strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("extractItemIDs(%s) = %q, %v; want %q, %v", tc.body, got, isList, tc.want, tc.isList)
+		}
+	}
+}
+
+func TestContainedItems(t *testing.T) {
+	got := containedItems([]string{"v2", "v1", "shared"}, []string{"shared", "a1"}, []string{"a1", "shared", "v1", "v2"})
+	if strings.Join(got, ",") != "v1,v2" {
+		t.Errorf("containedItems = %q, want the victim's own items only", got)
+	}
+}
+
+// List responses are judged by whose items they hold, not by size, so
+// changing pagination metadata and list lengths don't decide the finding
+func TestListFindingsIgnorePaginationMetadata(t *testing.T) {
+	orders := map[string][]string{"alice": {"o-1"}, "bob": {"o-2", "o-3", "o-4"}}
+	owner := map[string]string{"123": "alice", "456": "bob"}
+	token := map[string]string{"Bearer alice-token": "alice", "Bearer bob-token": "bob"}
+
+	for _, leaky := range []bool{true, false} {
+		page := 0
+		srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			caller, ok := token[r.Header.Get("Authorization")]
+			if !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			who := caller
+			if leaky {
+				who = owner[strings.Split(r.URL.Path, "/")[3]]
+			}
+			page++
+			items := []string{}
+			for _, id := range orders[who] {
+				items = append(items, fmt.Sprintf(`{"id":%q}`, id))
+			}
+			fmt.Fprintf(w, `{"data":[%s],"total":%d,"request":%d}`, strings.Join(items, ","), len(items), page)
+		}))
+		s := fastScanner(selftestUsers(), []APIRequest{getRequest(srv.URL + "/api/users/{user_id}/orders")})
+
+		findings, err := s.Scan(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		list := 0
+		for _, f := range findings {
+			if f.Kind == kindCrossUserList && f.Severity == SeverityCritical {
+				list++
+			} else if f.Kind != kindNoAuth {
+				t.Errorf("leaky=%v: unexpected %s %s finding", leaky, f.Severity, f.Kind)
+			}
+		}
+		if want := map[bool]int{true: 2, false: 0}[leaky]; list != want {
+			t.Errorf("leaky=%v: %d list findings, want %d", leaky, list, want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+// loadKnownFindings reads a previously approved report (any format replay
+// accepts) into the set of its finding IDs
+func loadKnownFindings(path string) (map[string]bool, error) {
+	findings, err := loadFindings(path)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(findings))
+	for _, f := range findings {
+		if f.ID == "" {
+			f.ID = findingID(f)
+		}
+		known[f.ID] = true
+	}
+	return known, nil
+}
+
+// SetKnownFindings marks finding IDs as already accepted. They are still
+// reported, but don't count toward the finding limit, so a known CRITICAL
+// doesn't stop a --stop-on-critical run before it reaches new ones.
+func (s *Scanner) SetKnownFindings(ids map[string]bool) {
+	s.known = ids
+}
+
+// splitKnown separates findings whose ID is in known from new ones
+func splitKnown(findings []Finding, known map[string]bool) (fresh, seen []Finding) {
+	fresh = []Finding{}
+	seen = []Finding{}
+	for _, f := range findings {
+		if known[f.ID] {
+			seen = append(seen, f)
+		} else {
+			fresh = append(fresh, f)
+		}
+	}
+	return fresh, seen
+}
+
+// gatedFindings counts findings that should fail a run: anything above INFO
+func gatedFindings(findings []Finding) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity != SeverityInfo {
+			n++
+		}
+	}
+	return n
+}
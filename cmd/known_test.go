@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLoadKnownFindings(t *testing.T) {
+	f := Finding{Severity: SeverityCritical, Kind: kindCrossUser, Attacker: "alice", Victim: "bob", Method: "GET", Endpoint: "/api/users/{user_id}"}
+	withID := f
+	withID.ID = "abc123"
+	data, err := json.Marshal(map[string][]Finding{"findings": {f, withID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	known, err := loadKnownFindings(writeTemp(t, "approved.json", string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(known) != 2 || !known[findingID(f)] || !known["abc123"] {
+		t.Errorf("known = %v, want %s and abc123", known, findingID(f))
+	}
+}
+
+func TestSplitKnown(t *testing.T) {
+	findings := []Finding{
+		{ID: "a", Severity: SeverityCritical},
+		{ID: "b", Severity: SeverityInfo},
+		{ID: "c", Severity: SeverityHigh},
+	}
+	fresh, seen := splitKnown(findings, map[string]bool{"a": true})
+	if len(fresh) != 2 || len(seen) != 1 || seen[0].ID != "a" {
+		t.Errorf("fresh = %+v, seen = %+v", fresh, seen)
+	}
+	if n := gatedFindings(fresh); n != 1 {
+		t.Errorf("gatedFindings = %d, want 1 (INFO doesn't fail a run)", n)
+	}
+}
+
+// known findings are still reported but don't trip --stop-on-critical, so
+// the scan goes on to the first new CRITICAL
+func TestKnownFindingsSkipLimit(t *testing.T) {
+	srv := newRecordingServer(t, selftestHandler())
+	requests := limitRequests(srv.URL)
+
+	first, err := fastScanner(selftestUsers(), requests[:1]).Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) == 0 {
+		t.Fatal("no findings on page=0")
+	}
+	known := map[string]bool{}
+	for _, f := range first {
+		known[f.ID] = true
+	}
+
+	s := fastScanner(selftestUsers(), requests)
+	s.SetKnownFindings(known)
+	s.SetFindingLimit(0, true)
+	s.SetLogger(&recordingLogger{})
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fresh, seen := splitKnown(findings, known)
+	if len(seen) != len(first) {
+		t.Errorf("%d known findings reported, want %d", len(seen), len(first))
+	}
+	if len(fresh) != 1 || fresh[0].Severity != SeverityCritical || !strings.Contains(fresh[0].Endpoint, "page=1") {
+		t.Errorf("new findings = %+v, want the first CRITICAL on page=1", fresh)
+	}
+}
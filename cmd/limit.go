@@ -0,0 +1,41 @@
+package cmd
+
+import "context"
+
+// SetFindingLimit stops the scan once max findings have been reported
+// (0 = no limit), or at the first CRITICAL when stopOnCritical is set
+func (s *Scanner) SetFindingLimit(max int, stopOnCritical bool) {
+	s.maxFindings = max
+	s.stopOnCritical = stopOnCritical
+}
+
+// limitContext derives the run's context, which addFinding cancels once the
+// finding limit is reached, and resets the run's finding counters
+func (s *Scanner) limitContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.found = 0
+	s.limitHit = false
+	s.requestErrors = nil
+	s.stop = cancel
+	s.runCtx = ctx
+	return ctx, cancel
+}
+
+// checkFindingLimit counts f and stops the scan if it hits the limit
+func (s *Scanner) checkFindingLimit(f Finding) {
+	s.found++
+	if s.stop == nil || s.limitHit {
+		return
+	}
+
+	switch {
+	case s.stopOnCritical && f.Severity == SeverityCritical:
+		s.log.Warnf("🛑 CRITICAL finding on %s %s; stopping scan\n", f.Method, f.Endpoint)
+	case s.maxFindings > 0 && s.found == s.maxFindings:
+		s.log.Warnf("🛑 Reached %d findings; stopping scan\n", s.maxFindings)
+	default:
+		return
+	}
+	s.limitHit = true
+	s.stop()
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// limitRequests are endpoints on the demo API that each yield cross-user findings
+func limitRequests(url string) []APIRequest {
+	requests := []APIRequest{}
+	for i := 0; i < 5; i++ {
+		requests = append(requests, getRequest(fmt.Sprintf("%s/api/users/{user_id}?page=%d", url, i)))
+	}
+	return requests
+}
+
+func TestMaxFindingsStopsScan(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		srv := newRecordingServer(t, selftestHandler())
+		s := fastScanner(selftestUsers(), limitRequests(srv.URL))
+		s.SetWorkers(workers)
+		s.SetFindingLimit(3, false)
+		s.SetLogger(&recordingLogger{})
+
+		findings, err := s.Scan(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(findings) != 3 || !s.limitHit {
+			t.Errorf("workers=%d: got %d findings (limit hit: %v), want 3", workers, len(findings), s.limitHit)
+		}
+	}
+}
+
+func TestStopOnCritical(t *testing.T) {
+	srv := newRecordingServer(t, selftestHandler())
+	s := fastScanner(selftestUsers(), limitRequests(srv.URL))
+	s.SetFindingLimit(0, true)
+	log := &recordingLogger{}
+	s.SetLogger(log)
+
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Severity != SeverityCritical {
+		t.Errorf("findings = %+v, want just the first CRITICAL", findings)
+	}
+	if warns := log.warnings(); len(warns) != 1 {
+		t.Errorf("warnings = %q", warns)
+	}
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// locationHeaders carry the URL of a newly created or moved resource
+var locationHeaders = []string{"Location", "Content-Location"}
+
+// isWriteMethod reports whether a request method can create or modify resources
+func isWriteMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	}
+	return false
+}
+
+// checkLocationIDOR flags a write whose 201 or redirect response points at a
+// resource under the victim's identifiers, i.e. the attacker created or moved
+// something inside the victim's namespace. Redirects the client followed are
+// inspected through the response chain.
+func checkLocationIDOR(req APIRequest, attacker, victim User, resp *http.Response) *Finding {
+	if !isWriteMethod(req.Method) {
+		return nil
+	}
+
+	for r := resp; r != nil; r = priorResponse(r) {
+		if r.StatusCode != http.StatusCreated && (r.StatusCode < 300 || r.StatusCode > 399) {
+			continue
+		}
+
+		for _, name := range locationHeaders {
+			loc := r.Header.Get(name)
+			if loc == "" {
+				continue
+			}
+
+			key, val, ok := victimIDInLocation(loc, attacker, victim)
+			if !ok {
+				continue
+			}
+
+			return &Finding{
+				Kind:        kindCrossUserWrite,
+				Severity:    SeverityCritical,
+				Endpoint:    req.URL,
+				Method:      req.Method,
+				Description: fmt.Sprintf("%s created a resource under '%s's %s (cross-user write)", attackerLabel(attacker), victim.Name, key),
+				Evidence:    fmt.Sprintf("Status: %d, %s: %s (%s=%s)", r.StatusCode, name, loc, key, val),
+				Timestamp:   time.Now(),
+				Attacker:    attacker.Name,
+				Victim:      victim.Name,
+				Anonymous:   attacker.IsAnonymous(),
+				Credential:  attacker.credential,
+			}
+		}
+	}
+
+	return nil
+}
+
+// priorResponse returns the redirect response that led to r, if any
+func priorResponse(r *http.Response) *http.Response {
+	if r.Request == nil {
+		return nil
+	}
+	return r.Request.Response
+}
+
+// victimIDInLocation finds a victim param value where loc names that param:
+// the path segment after its resource (user_id=456 in /users/456/...) or the
+// query value under its key. A value elsewhere, such as the new object's own
+// ID, is a coincidence. Values the attacker shares are ignored.
+func victimIDInLocation(loc string, attacker, victim User) (string, string, bool) {
+	u, err := url.Parse(loc)
+	if err != nil {
+		return "", "", false
+	}
+
+	segments := strings.Split(u.Path, "/")
+	query := u.Query()
+
+	for _, key := range sortedParamKeys(victim.Params) {
+		val := victim.Params[key]
+		if val == "" || attacker.Params[key] == val {
+			continue
+		}
+		for _, v := range query[key] {
+			if v == val {
+				return key, val, true
+			}
+		}
+		resource := paramResource(key)
+		if resource == "" {
+			continue
+		}
+		for i := 1; i < len(segments); i++ {
+			if segments[i] == val && strings.TrimSuffix(strings.ToLower(segments[i-1]), "s") == resource {
+				return key, val, true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+// paramResource is the resource a param identifies: "user" for user_id,
+// userId or user-id, "" for a bare id
+func paramResource(key string) string {
+	k := strings.ToLower(key)
+	for _, suffix := range []string{"_uuid", "uuid", "_id", "-id", "id"} {
+		if strings.HasSuffix(k, suffix) {
+			return strings.TrimSuffix(strings.TrimSuffix(k, suffix), "s")
+		}
+	}
+	return ""
+}
+
+func sortedParamKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import "testing"
+
+func TestVictimIDInLocation(t *testing.T) {
+	attacker := User{Name: "alice", Params: map[string]string{"user_id": "12"}}
+	victim := User{Name: "bob", Params: map[string]string{"user_id": "7"}}
+
+	for _, tc := range []struct {
+		loc  string
+		want bool
+	}{
+		{"/api/users/7/orders/991", true},
+		{"https://api.example.com/api/user/7", true},
+		{"/api/orders?user_id=7", true},
+		// The new object's own ID is the victim's only by coincidence
+		{"/api/users/12/orders/7", false},
+		{"/api/orders/7", false},
+		{"/api/orders?page=7", false},
+	} {
+		_, _, got := victimIDInLocation(tc.loc, attacker, victim)
+		if got != tc.want {
+			t.Errorf("victimIDInLocation(%q) = %v, want %v", tc.loc, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Logger receives the scanner's progress and warning output. Formats follow
+// fmt.Printf and carry their own trailing newline.
+type Logger interface {
+	Debugf(format string, args ...any) // progress detail, shown with -v
+	Warnf(format string, args ...any)  // problems worth surfacing even when quiet
+}
+
+// nopLogger discards everything; it is the library default
+type nopLogger struct{}
+
+func (nopLogger) Debugf(string, ...any) {}
+func (nopLogger) Warnf(string, ...any)  {}
+
+// cliLogger writes progress to stdout when verbose and warnings to stderr
+type cliLogger struct {
+	verbose bool
+	out     io.Writer
+	err     io.Writer
+}
+
+func newCLILogger(verbose bool) *cliLogger {
+	return &cliLogger{verbose: verbose, out: os.Stdout, err: os.Stderr}
+}
+
+func (l *cliLogger) Debugf(format string, args ...any) {
+	if l.verbose {
+		fmt.Fprintf(l.out, format, args...)
+	}
+}
+
+func (l *cliLogger) Warnf(format string, args ...any) {
+	fmt.Fprintf(l.err, format, args...)
+}
+
+// SetLogger routes scanner output to l (nil silences it)
+func (s *Scanner) SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	s.log = l
+	s.throttle.log = l
+}
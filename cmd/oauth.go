@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin re-fetches a token this long before it expires
+const tokenRefreshMargin = 30 * time.Second
+
+// OAuthConfig obtains a user's bearer token with the OAuth2 client-credentials grant
+type OAuthConfig struct {
+	TokenEndpoint string `json:"token_endpoint"`
+	ClientID      string `json:"client_id"`
+	ClientSecret  string `json:"client_secret"`
+	Scope         string `json:"scope,omitempty"`
+}
+
+// oauthToken is a cached access token
+type oauthToken struct {
+	value   string
+	expires time.Time // zero when the server gave no expires_in
+}
+
+// tokenCache holds one token per user, refreshed when close to expiry
+type tokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]oauthToken
+}
+
+// PrefetchTokens acquires every OAuth user's token up front so bad client
+// credentials fail the scan before any test traffic is sent
+func (s *Scanner) PrefetchTokens(ctx context.Context) error {
+	for _, user := range s.Users {
+		if user.OAuth == nil {
+			continue
+		}
+		if _, err := s.accessToken(ctx, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyOAuth sets the user's bearer token on req. Credential-set contexts
+// bring their own Authorization and are left alone.
+func (s *Scanner) applyOAuth(user User, req *http.Request) error {
+	if user.OAuth == nil || user.credential != "" {
+		return nil
+	}
+	token, err := s.accessToken(req.Context(), user)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// accessToken returns the user's cached token, fetching a new one when it is
+// missing or about to expire
+func (s *Scanner) accessToken(ctx context.Context, user User) (string, error) {
+	s.oauth.mu.Lock()
+	defer s.oauth.mu.Unlock()
+
+	if tok, ok := s.oauth.tokens[user.Name]; ok {
+		if tok.expires.IsZero() || time.Until(tok.expires) > tokenRefreshMargin {
+			return tok.value, nil
+		}
+	}
+
+	s.log.Debugf("🔑 Fetching OAuth token for %s from %s\n", user.Name, user.OAuth.TokenEndpoint)
+
+	tok, err := s.fetchToken(ctx, user.OAuth)
+	if err != nil {
+		return "", fmt.Errorf("oauth token for user '%s': %w", user.Name, err)
+	}
+	s.oauth.tokens[user.Name] = tok
+	return tok.value, nil
+}
+
+// fetchToken performs the client-credentials grant, authenticating the client
+// with HTTP Basic as RFC 6749 requires servers to support
+func (s *Scanner) fetchToken(ctx context.Context, cfg *OAuthConfig) (oauthToken, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if cfg.Scope != "" {
+		form.Set("scope", cfg.Scope)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return oauthToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return oauthToken{}, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		if detail := strings.TrimSpace(string(body)); detail != "" {
+			return oauthToken{}, fmt.Errorf("token endpoint returned %s: %s", resp.Status, detail)
+		}
+		return oauthToken{}, fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return oauthToken{}, fmt.Errorf("parsing token response: %w", err)
+	}
+	if payload.AccessToken == "" {
+		return oauthToken{}, fmt.Errorf("token response has no access_token")
+	}
+
+	tok := oauthToken{value: payload.AccessToken}
+	if payload.ExpiresIn > 0 {
+		tok.expires = time.Now().Add(time.Duration(payload.ExpiresIn) * time.Second)
+	}
+	return tok, nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// tokenServer grants "token-<client>" to clients whose secret is "s3cret",
+// valid for expiresIn seconds, counting the grants it issues
+func tokenServer(t *testing.T, expiresIn int, grants *atomic.Int32) *recordingServer {
+	return newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if r.Method != http.MethodPost || r.FormValue("grant_type") != "client_credentials" || secret != "s3cret" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		grants.Add(1)
+		fmt.Fprintf(w, `{"access_token":"token-%s","token_type":"bearer","expires_in":%d}`, id, expiresIn)
+	}))
+}
+
+func oauthUser(name, endpoint, secret string) User {
+	return User{Name: name, OAuth: &OAuthConfig{TokenEndpoint: endpoint, ClientID: name, ClientSecret: secret}}
+}
+
+// Each user's requests carry their own granted token, fetched once while it
+// stays valid
+func TestOAuthTokensPerUser(t *testing.T) {
+	var grants atomic.Int32
+	tokens := tokenServer(t, 3600, &grants)
+	srv := newRecordingServer(t, selftestHandler())
+
+	users := []User{oauthUser("alice", tokens.URL, "s3cret"), oauthUser("bob", tokens.URL, "s3cret")}
+	users[0].Params = map[string]string{"user_id": "123"}
+	users[1].Params = map[string]string{"user_id": "456"}
+	s := fastScanner(users, []APIRequest{getRequest(srv.URL + "/api/users/{user_id}")})
+	if err := s.PrefetchTokens(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := grants.Load(); n != 2 {
+		t.Errorf("token endpoint granted %d tokens, want one per user", n)
+	}
+	seen := map[string]bool{}
+	for _, r := range srv.requests() {
+		auth := r.Header.Get("Authorization")
+		if auth == "" {
+			continue // the no-auth test
+		}
+		if !strings.HasPrefix(auth, "Bearer token-") {
+			t.Errorf("%s %s sent Authorization %q", r.Method, r.Path, auth)
+		}
+		seen[auth] = true
+	}
+	if !seen["Bearer token-alice"] || !seen["Bearer token-bob"] {
+		t.Errorf("tokens sent = %v, want both users'", seen)
+	}
+}
+
+// A token within the refresh margin of expiring is fetched again
+func TestOAuthRefreshesExpiringToken(t *testing.T) {
+	var grants atomic.Int32
+	tokens := tokenServer(t, 10, &grants)
+	s := NewScanner([]User{oauthUser("alice", tokens.URL, "s3cret")}, nil)
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.accessToken(context.Background(), s.Users[0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := grants.Load(); n != 3 {
+		t.Errorf("granted %d tokens for 3 calls, want a fresh one each time", n)
+	}
+}
+
+func TestPrefetchTokensFailsOnBadCredentials(t *testing.T) {
+	var grants atomic.Int32
+	tokens := tokenServer(t, 3600, &grants)
+	s := NewScanner([]User{oauthUser("alice", tokens.URL, "wrong")}, nil)
+
+	err := s.PrefetchTokens(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "user 'alice'") || !strings.Contains(err.Error(), "invalid_client") {
+		t.Errorf("PrefetchTokens error = %v", err)
+	}
+}
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 )
@@ -19,15 +23,29 @@ func outputText(findings []Finding) {
 
 	for i, f := range findings {
 		icon := "🔴"
-		if f.Severity == "HIGH" {
+		if f.Severity == SeverityHigh {
 			icon = "🟠"
-		} else if f.Severity == "MEDIUM" {
+		} else if f.Severity == SeverityMedium {
 			icon = "🟡"
+		} else if f.Severity == SeverityInfo {
+			icon = "ℹ️ "
 		}
 
-		fmt.Printf("%s [%s] %s %s\n", icon, f.Severity, f.Method, f.Endpoint)
+		fmt.Printf("%s [%s] %s %s  (%s)\n", icon, f.Severity.Label(), f.Method, f.Endpoint, f.ID)
 		fmt.Printf("   %s\n", f.Description)
 		fmt.Printf("   %s\n", f.Evidence)
+		if f.Sensitivity != "" {
+			fmt.Printf("   🏷️  Sensitivity: %s\n", f.Sensitivity)
+		}
+		if f.SizeMismatch {
+			fmt.Println("   ⚠️  Content-Length disagreed with the bytes read; sizes may be unreliable")
+		}
+		if f.BaselineDrift {
+			fmt.Println("   ⚠️  The endpoint's baseline drifted during the scan; re-check against a fresh one")
+		}
+		if rows := headerRows(f); len(rows) > 0 {
+			printHeaderTable(rows)
+		}
 		
 		if i < len(findings)-1 {
 			fmt.Println()
@@ -35,6 +53,19 @@ func outputText(findings []Finding) {
 	}
 }
 
+// printHeaderTable prints a finding's response headers beside the baseline's
+func printHeaderTable(rows []headerRow) {
+	nameWidth, respWidth := len("Header"), len("Response")
+	for _, r := range rows {
+		nameWidth = max(nameWidth, len(r.Name))
+		respWidth = max(respWidth, len(r.Response))
+	}
+	fmt.Printf("   %-*s  %-*s  %s\n", nameWidth, "Header", respWidth, "Response", "Baseline")
+	for _, r := range rows {
+		fmt.Printf("   %-*s  %-*s  %s\n", nameWidth, r.Name, respWidth, r.Response, r.Baseline)
+	}
+}
+
 func formatJSON(findings []Finding) string {
 	output := struct {
 		Findings  []Finding `json:"findings"`
@@ -52,21 +83,136 @@ func formatJSON(findings []Finding) string {
 	return string(data)
 }
 
+// formatJSONL renders one JSON finding per line (stream-friendly)
+func formatJSONL(findings []Finding) string {
+	var buf strings.Builder
+	for _, f := range findings {
+		data, _ := json.Marshal(f)
+		buf.Write(data)
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// csvHeader lists the columns written by formatCSV and the CSV stream
+var csvHeader = []string{"severity", "method", "endpoint", "description", "evidence", "timestamp"}
+
+func csvRecord(f Finding) []string {
+	return []string{f.Severity.Label(), f.Method, f.Endpoint, f.Description, f.Evidence, f.Timestamp.Format(time.RFC3339)}
+}
+
+func formatCSV(findings []Finding) string {
+	var buf strings.Builder
+	w := csv.NewWriter(&buf)
+	w.Write(csvHeader)
+	for _, f := range findings {
+		w.Write(csvRecord(f))
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// redactHeader masks credential header values in human-readable reports (text,
+// HTML, curl). An Authorization scheme is kept so the report still shows
+// Bearer or Basic. JSON and JSONL keep raw values for replay.
+func redactHeader(name, value string) string {
+	if !isAuthName(name) || value == "" {
+		return value
+	}
+	if scheme, _, ok := strings.Cut(value, " "); ok && strings.HasSuffix(http.CanonicalHeaderKey(name), "Authorization") {
+		return scheme + " ***"
+	}
+	return "***"
+}
+
+// redactQuery masks the values of credential query parameters in rawURL:
+// those named in keys (lower-cased) and those isAuthName matches
+func redactQuery(rawURL string, keys map[string]bool) string {
+	base, query, ok := strings.Cut(rawURL, "?")
+	if !ok {
+		return rawURL
+	}
+	query, fragment, hasFragment := strings.Cut(query, "#")
+
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		raw, _, _ := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(raw)
+		if err != nil {
+			name = raw
+		}
+		if keys[strings.ToLower(name)] || isAuthName(name) {
+			pairs[i] = raw + "=***"
+		}
+	}
+
+	masked := base + "?" + strings.Join(pairs, "&")
+	if hasFragment {
+		masked += "#" + fragment
+	}
+	return masked
+}
+
+// maskedURL is the request's URL with credential query values masked
+func (r *RecordedRequest) maskedURL() string {
+	keys := make(map[string]bool, len(r.AuthQuery))
+	for _, name := range r.AuthQuery {
+		keys[strings.ToLower(name)] = true
+	}
+	return redactQuery(r.URL, keys)
+}
+
+// curlCommand renders a recorded request as a copy-pasteable curl
+// reproduction, with credentials masked (see redactHeader and redactQuery)
+func curlCommand(req *RecordedRequest) string {
+	if req == nil {
+		return ""
+	}
+
+	quote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+
+	parts := []string{"curl", "-i", "-X", req.Method}
+
+	keys := make([]string, 0, len(req.Headers))
+	for key := range req.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, val := range req.Headers[key] {
+			parts = append(parts, "-H", quote(key+": "+redactHeader(key, val)))
+		}
+	}
+
+	if req.Body != "" {
+		parts = append(parts, "--data-raw", quote(req.Body))
+	}
+
+	parts = append(parts, quote(req.maskedURL()))
+	return strings.Join(parts, " ")
+}
+
 func formatHTML(findings []Finding) string {
 	critical := 0
 	high := 0
 	medium := 0
+	info := 0
 	for _, f := range findings {
 		switch f.Severity {
-		case "CRITICAL":
+		case SeverityCritical:
 			critical++
-		case "HIGH":
+		case SeverityHigh:
 			high++
-		case "MEDIUM":
+		case SeverityMedium:
 			medium++
+		case SeverityInfo:
+			info++
 		}
 	}
 
+	// Self-contained: all styles and scripts are inline so the report works offline
 	tmpl := `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -78,24 +224,40 @@ func formatHTML(findings []Finding) string {
         body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; background: #0d1117; color: #c9d1d9; padding: 2rem; }
         .container { max-width: 1200px; margin: 0 auto; }
         h1 { color: #58a6ff; margin-bottom: 0.5rem; }
+        h3 { color: #8b949e; font-size: 0.75rem; text-transform: uppercase; margin: 1rem 0 0.25rem; }
         .subtitle { color: #8b949e; margin-bottom: 2rem; }
         .summary { display: flex; gap: 1rem; margin-bottom: 2rem; }
-        .stat { background: #161b22; padding: 1rem 1.5rem; border-radius: 8px; border: 1px solid #30363d; }
+        .stat { background: #161b22; padding: 1rem 1.5rem; border-radius: 8px; border: 1px solid #30363d; cursor: pointer; user-select: none; }
+        .stat.off { opacity: 0.35; }
         .stat-value { font-size: 2rem; font-weight: bold; }
         .stat-label { color: #8b949e; font-size: 0.875rem; }
         .critical .stat-value { color: #f85149; }
         .high .stat-value { color: #db6d28; }
         .medium .stat-value { color: #d29922; }
-        .finding { background: #161b22; border: 1px solid #30363d; border-radius: 8px; padding: 1.5rem; margin-bottom: 1rem; }
-        .finding-header { display: flex; align-items: center; gap: 0.75rem; margin-bottom: 0.75rem; }
+        .info .stat-value { color: #8b949e; }
+        .toolbar { display: flex; gap: 0.75rem; margin-bottom: 1rem; }
+        .toolbar input { flex: 1; background: #161b22; border: 1px solid #30363d; border-radius: 6px; color: #c9d1d9; padding: 0.5rem 0.75rem; }
+        table { width: 100%; border-collapse: collapse; background: #161b22; border: 1px solid #30363d; border-radius: 8px; }
+        th { text-align: left; color: #8b949e; font-size: 0.75rem; text-transform: uppercase; padding: 0.75rem; border-bottom: 1px solid #30363d; cursor: pointer; user-select: none; }
+        th.asc::after { content: " ▲"; }
+        th.desc::after { content: " ▼"; }
+        td { padding: 0.75rem; border-bottom: 1px solid #21262d; vertical-align: top; }
+        tr.row { cursor: pointer; }
+        tr.row:hover { background: #1c2129; }
+        tr.detail td { background: #0d1117; }
         .severity { padding: 0.25rem 0.5rem; border-radius: 4px; font-size: 0.75rem; font-weight: 600; text-transform: uppercase; }
         .severity-critical { background: #f8514933; color: #f85149; }
         .severity-high { background: #db6d2833; color: #db6d28; }
         .severity-medium { background: #d2992233; color: #d29922; }
+        .severity-info { background: #8b949e33; color: #8b949e; }
+        .tag { padding: 0.1rem 0.4rem; border-radius: 4px; font-size: 0.75rem; background: #1f6feb33; color: #58a6ff; }
         .method { font-family: monospace; background: #30363d; padding: 0.25rem 0.5rem; border-radius: 4px; }
         .endpoint { font-family: monospace; color: #58a6ff; word-break: break-all; }
-        .description { margin-bottom: 0.5rem; }
         .evidence { color: #8b949e; font-family: monospace; font-size: 0.875rem; }
+        pre { background: #161b22; border: 1px solid #30363d; border-radius: 6px; padding: 0.75rem; font-size: 0.8rem; white-space: pre-wrap; word-break: break-all; }
+        table.diff { margin-bottom: 0.5rem; font-size: 0.8rem; }
+        table.diff th, table.diff td { padding: 0.35rem 0.6rem; }
+        .empty { background: #161b22; border: 1px solid #30363d; border-radius: 8px; padding: 1.5rem; }
         .footer { margin-top: 2rem; padding-top: 1rem; border-top: 1px solid #30363d; color: #8b949e; font-size: 0.875rem; }
         a { color: #58a6ff; }
     </style>
@@ -104,19 +266,23 @@ func formatHTML(findings []Finding) string {
     <div class="container">
         <h1>🔍 IDOR-Scan Report</h1>
         <p class="subtitle">Generated: {{.Timestamp}}</p>
-        
+
         <div class="summary">
-            <div class="stat critical">
+            <div class="stat critical" data-filter="CRITICAL">
                 <div class="stat-value">{{.Critical}}</div>
-                <div class="stat-label">Critical</div>
+                <div class="stat-label">{{.CriticalLabel}}</div>
             </div>
-            <div class="stat high">
+            <div class="stat high" data-filter="HIGH">
                 <div class="stat-value">{{.High}}</div>
-                <div class="stat-label">High</div>
+                <div class="stat-label">{{.HighLabel}}</div>
             </div>
-            <div class="stat medium">
+            <div class="stat medium" data-filter="MEDIUM">
                 <div class="stat-value">{{.Medium}}</div>
-                <div class="stat-label">Medium</div>
+                <div class="stat-label">{{.MediumLabel}}</div>
+            </div>
+            <div class="stat info" data-filter="INFO">
+                <div class="stat-value">{{.Info}}</div>
+                <div class="stat-label">{{.InfoLabel}}</div>
             </div>
             <div class="stat">
                 <div class="stat-value">{{.Total}}</div>
@@ -124,22 +290,71 @@ func formatHTML(findings []Finding) string {
             </div>
         </div>
 
-        {{range .Findings}}
-        <div class="finding">
-            <div class="finding-header">
-                <span class="severity severity-{{.SeverityLower}}">{{.Severity}}</span>
-                <span class="method">{{.Method}}</span>
-                <span class="endpoint">{{.Endpoint}}</span>
-            </div>
-            <p class="description">{{.Description}}</p>
-            <p class="evidence">{{.Evidence}}</p>
-        </div>
-        {{end}}
-
         {{if eq .Total 0}}
-        <div class="finding">
+        <div class="empty">
             <p>✅ No IDOR vulnerabilities detected</p>
         </div>
+        {{else}}
+        <div class="toolbar">
+            <input id="search" type="search" placeholder="Filter by endpoint or description...">
+        </div>
+
+        <table id="findings">
+            <thead>
+                <tr>
+                    <th data-key="rank">Severity</th>
+                    <th data-key="method">Method</th>
+                    <th data-key="endpoint">Endpoint</th>
+                    <th data-key="description">Description</th>
+                </tr>
+            </thead>
+            {{range .Findings}}
+            <tbody class="finding" data-severity="{{.Severity}}" data-rank="{{.Rank}}" data-method="{{.Method}}" data-endpoint="{{.Endpoint}}" data-description="{{.Description}}">
+                <tr class="row">
+                    <td><span class="severity severity-{{.SeverityLower}}">{{.Label}}</span></td>
+                    <td><span class="method">{{.Method}}</span></td>
+                    <td><span class="endpoint">{{.Endpoint}}</span></td>
+                    <td>{{.Description}}{{if .Sensitivity}} <span class="tag">{{.Sensitivity}}</span>{{end}}</td>
+                </tr>
+                <tr class="detail" hidden>
+                    <td colspan="4">
+                        <h3>Evidence</h3>
+                        <p class="evidence">{{.Evidence}}</p>
+                        {{if .Request}}
+                        <h3>Request</h3>
+                        <pre>{{.Request}}</pre>
+                        {{end}}
+                        {{if .Headers}}
+                        <h3>Response Headers</h3>
+                        <table class="diff">
+                            <tr><th>Header</th><th>Response</th><th>Victim's baseline</th></tr>
+                            {{range .Headers}}
+                            <tr><td><code>{{.Name}}</code></td><td><code>{{.Response}}</code></td><td><code>{{.Baseline}}</code></td></tr>
+                            {{end}}
+                        </table>
+                        {{end}}
+                        {{if .Diff}}
+                        <h3>Leaked Fields</h3>
+                        <table class="diff">
+                            <tr><th>Field</th><th>Change</th><th>Attacker got</th><th>Attacker's own</th></tr>
+                            {{range .Diff}}
+                            <tr><td><code>{{.Path}}</code></td><td>{{.Change}}</td><td><code>{{.Value}}</code></td><td><code>{{.Was}}</code></td></tr>
+                            {{end}}
+                        </table>
+                        {{end}}
+                        {{if .Response}}
+                        <h3>Response Snippet</h3>
+                        <pre>{{.Response}}</pre>
+                        {{end}}
+                        {{if .Curl}}
+                        <h3>Reproduce</h3>
+                        <pre>{{.Curl}}</pre>
+                        {{end}}
+                    </td>
+                </tr>
+            </tbody>
+            {{end}}
+        </table>
         {{end}}
 
         <div class="footer">
@@ -147,44 +362,137 @@ func formatHTML(findings []Finding) string {
             <p>Need help? <a href="https://idor-scan.dev/consulting">Book an API security audit</a></p>
         </div>
     </div>
+    <script>
+    (function () {
+        var table = document.getElementById("findings");
+        if (!table) { return; }
+        var hidden = {};
+        var search = document.getElementById("search");
+
+        function apply() {
+            var q = search.value.toLowerCase();
+            table.querySelectorAll("tbody.finding").forEach(function (tb) {
+                var text = (tb.dataset.endpoint + " " + tb.dataset.description).toLowerCase();
+                tb.hidden = hidden[tb.dataset.severity] || (q !== "" && text.indexOf(q) === -1);
+            });
+        }
+
+        document.querySelectorAll(".stat[data-filter]").forEach(function (el) {
+            el.addEventListener("click", function () {
+                var sev = el.dataset.filter;
+                hidden[sev] = !hidden[sev];
+                el.classList.toggle("off", hidden[sev]);
+                apply();
+            });
+        });
+        search.addEventListener("input", apply);
+
+        table.querySelectorAll("tr.row").forEach(function (row) {
+            row.addEventListener("click", function () {
+                var detail = row.nextElementSibling;
+                detail.hidden = !detail.hidden;
+            });
+        });
+
+        table.querySelectorAll("th[data-key]").forEach(function (th) {
+            th.addEventListener("click", function () {
+                var key = th.dataset.key;
+                var asc = !th.classList.contains("asc");
+                table.querySelectorAll("th").forEach(function (h) { h.classList.remove("asc", "desc"); });
+                th.classList.add(asc ? "asc" : "desc");
+                var bodies = Array.prototype.slice.call(table.querySelectorAll("tbody.finding"));
+                bodies.sort(function (a, b) {
+                    var x = a.dataset[key], y = b.dataset[key];
+                    var cmp = key === "rank" ? x - y : x.localeCompare(y);
+                    return asc ? cmp : -cmp;
+                });
+                bodies.forEach(function (tb) { table.appendChild(tb); });
+            });
+        });
+    })();
+    </script>
 </body>
 </html>`
 
 	type FindingView struct {
 		Severity      string
 		SeverityLower string
+		Label         string
+		Rank          int
 		Method        string
 		Endpoint      string
 		Description   string
 		Evidence      string
+		Request       string
+		Response      string
+		Curl          string
+		Diff          []DiffEntry
+		Headers       []headerRow
+		Sensitivity   string
 	}
 
 	var findingViews []FindingView
 	for _, f := range findings {
-		findingViews = append(findingViews, FindingView{
-			Severity:      f.Severity,
-			SeverityLower: strings.ToLower(f.Severity),
+		view := FindingView{
+			Severity:      string(f.Severity),
+			SeverityLower: strings.ToLower(string(f.Severity)),
+			Label:         f.Severity.Label(),
+			Rank:          f.Severity.Rank(),
 			Method:        f.Method,
 			Endpoint:      f.Endpoint,
 			Description:   f.Description,
 			Evidence:      f.Evidence,
-		})
+			Response:      f.Response,
+			Diff:          f.Diff,
+			Headers:       headerRows(f),
+			Sensitivity:   f.Sensitivity,
+		}
+		if f.Request != nil {
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "%s %s\n", f.Request.Method, f.Request.maskedURL())
+			keys := make([]string, 0, len(f.Request.Headers))
+			for key := range f.Request.Headers {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				for _, val := range f.Request.Headers[key] {
+					fmt.Fprintf(&sb, "%s: %s\n", key, redactHeader(key, val))
+				}
+			}
+			if f.Request.Body != "" {
+				sb.WriteString("\n" + f.Request.Body)
+			}
+			view.Request = sb.String()
+			view.Curl = curlCommand(f.Request)
+		}
+		findingViews = append(findingViews, view)
 	}
 
 	data := struct {
-		Findings  []FindingView
-		Critical  int
-		High      int
-		Medium    int
-		Total     int
-		Timestamp string
+		Findings      []FindingView
+		Critical      int
+		High          int
+		Medium        int
+		Info          int
+		CriticalLabel string
+		HighLabel     string
+		MediumLabel   string
+		InfoLabel     string
+		Total         int
+		Timestamp     string
 	}{
-		Findings:  findingViews,
-		Critical:  critical,
-		High:      high,
-		Medium:    medium,
-		Total:     len(findings),
-		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+		Findings:      findingViews,
+		Critical:      critical,
+		High:          high,
+		Medium:        medium,
+		Info:          info,
+		CriticalLabel: SeverityCritical.Title(),
+		HighLabel:     SeverityHigh.Title(),
+		MediumLabel:   SeverityMedium.Title(),
+		InfoLabel:     SeverityInfo.Title(),
+		Total:         len(findings),
+		Timestamp:     time.Now().Format("2006-01-02 15:04:05"),
 	}
 
 	t, _ := template.New("report").Parse(tmpl)
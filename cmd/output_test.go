@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRedactHeader(t *testing.T) {
+	tests := []struct {
+		name, value, want string
+	}{
+		{"Authorization", "Bearer eyJhbGciOi", "Bearer ***"},
+		{"Proxy-Authorization", "Basic dXNlcjpwYXNz", "Basic ***"},
+		{"Cookie", "sid=abc; theme=dark", "***"},
+		{"X-API-Key", "k-123", "***"},
+		{"X-Auth-Token", "t-456", "***"},
+		{"Accept", "application/json", "application/json"},
+		{"Authorization", "", ""},
+	}
+	for _, tt := range tests {
+		if got := redactHeader(tt.name, tt.value); got != tt.want {
+			t.Errorf("redactHeader(%q, %q) = %q, want %q", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+func recordedFinding() Finding {
+	return Finding{
+		ID:       "abc123",
+		Severity: SeverityCritical,
+		Method:   "GET",
+		Endpoint: "https://api.example.com/users/456",
+		Request: &RecordedRequest{
+			Method: "GET",
+			URL:    "https://api.example.com/users/456",
+			Headers: http.Header{
+				"Authorization": {"Bearer secret-token"},
+				"Cookie":        {"sid=secret-session"},
+				"Accept":        {"application/json"},
+			},
+		},
+		ResponseHeaders: map[string]string{"Set-Cookie": "sid=secret-set"},
+	}
+}
+
+func TestCurlCommandMasksCredentials(t *testing.T) {
+	cmd := curlCommand(recordedFinding().Request)
+	for _, secret := range []string{"secret-token", "secret-session"} {
+		if strings.Contains(cmd, secret) {
+			t.Errorf("curl command leaks %q: %s", secret, cmd)
+		}
+	}
+	for _, want := range []string{"'Authorization: Bearer ***'", "'Cookie: ***'", "'Accept: application/json'"} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("curl command missing %s: %s", want, cmd)
+		}
+	}
+}
+
+func TestFormatHTMLMasksCredentials(t *testing.T) {
+	html := formatHTML([]Finding{recordedFinding()})
+	for _, secret := range []string{"secret-token", "secret-session", "secret-set"} {
+		if strings.Contains(html, secret) {
+			t.Errorf("HTML report leaks %q", secret)
+		}
+	}
+}
+
+func TestFormatJSONKeepsCredentials(t *testing.T) {
+	// Replay resends the recorded request, so JSON keeps it raw
+	if out := formatJSON([]Finding{recordedFinding()}); !strings.Contains(out, "Bearer secret-token") {
+		t.Error("JSON report dropped the recorded Authorization value")
+	}
+}
+
+func TestFormatHTMLHasInfoFilter(t *testing.T) {
+	html := formatHTML([]Finding{{Severity: SeverityInfo, Method: "GET", Endpoint: "https://api.example.com/x"}})
+	if !strings.Contains(html, `data-filter="INFO"`) {
+		t.Error("HTML report has no INFO filter card")
+	}
+}
+
+func TestCustomSeverityLabels(t *testing.T) {
+	if err := SetSeverityLabels(map[string]string{"critical": "P0"}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetSeverityLabels(nil)
+
+	findings := []Finding{recordedFinding()}
+	if csv := formatCSV(findings); !strings.Contains(csv, "\nP0,GET,") {
+		t.Errorf("CSV report doesn't use the label:\n%s", csv)
+	}
+	if html := formatHTML(findings); !strings.Contains(html, "P0") {
+		t.Error("HTML report doesn't use the label")
+	}
+	if out := formatJSON(findings); !strings.Contains(out, `"CRITICAL"`) || strings.Contains(out, "P0") {
+		t.Errorf("JSON report should keep the canonical level: %s", out)
+	}
+}
+
+// Query-string credentials are masked in the HTML report and curl line like
+// header ones
+func TestReportsMaskAuthParams(t *testing.T) {
+	srv := newRecordingServer(t, selftestHandler())
+	users := selftestUsers()
+	users[0].AuthParams = map[string]string{"sig": "alice-query-secret"}
+	users[1].AuthParams = map[string]string{"sig": "bob-query-secret"}
+	req := getRequest(srv.URL + "/api/users/{user_id}?view=full")
+
+	findings, err := fastScanner(users, []APIRequest{req}).Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recorded *RecordedRequest
+	for _, f := range findings {
+		if f.Request != nil && strings.Contains(f.Request.URL, "query-secret") {
+			recorded = f.Request
+		}
+	}
+	if recorded == nil {
+		t.Fatalf("no finding recorded a request with the auth param: %+v", findings)
+	}
+
+	out := formatHTML(findings) + curlCommand(recorded)
+	if strings.Contains(out, "query-secret") {
+		t.Error("HTML report or curl line leaks an auth_params value")
+	}
+	for _, want := range []string{"sig=***", "view=full"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct{ url, want string }{
+		{"https://a.test/x?access_token=t1&page=2", "https://a.test/x?access_token=***&page=2"},
+		{"https://a.test/x?sig=s1#top", "https://a.test/x?sig=***#top"},
+		{"https://a.test/x?author=me", "https://a.test/x?author=me"},
+		{"https://a.test/x", "https://a.test/x"},
+	}
+	for _, tt := range tests {
+		if got := redactQuery(tt.url, map[string]bool{"sig": true}); got != tt.want {
+			t.Errorf("redactQuery(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -19,8 +21,27 @@ type PostmanCollection struct {
 
 type PostmanItem struct {
 	Name    string            `json:"name"`
-	Request PostmanRequest    `json:"request"`
+	Request *PostmanRequest   `json:"request"`
 	Item    []PostmanItem     `json:"item"` // For folders
+
+	invalid error // why the item couldn't be decoded, if it couldn't
+}
+
+// UnmarshalJSON keeps a malformed item in place, marked invalid, so one bad
+// item doesn't fail the whole collection
+func (pi *PostmanItem) UnmarshalJSON(data []byte) error {
+	type item PostmanItem
+	var v item
+	if err := json.Unmarshal(data, &v); err != nil {
+		var named struct {
+			Name string `json:"name"`
+		}
+		json.Unmarshal(data, &named)
+		*pi = PostmanItem{Name: named.Name, invalid: err}
+		return nil
+	}
+	*pi = PostmanItem(v)
+	return nil
 }
 
 type PostmanRequest struct {
@@ -46,7 +67,29 @@ type PostmanBody struct {
 	Raw  string `json:"raw"`
 }
 
-func parsePostmanCollection(filename string) ([]APIRequest, error) {
+// skipLog warns about input items a parser couldn't use and counts them, so
+// a few broken entries don't abort a large import
+type skipLog struct {
+	source string
+	count  int
+	log    Logger
+}
+
+func (l *skipLog) skip(item string, err error) {
+	l.count++
+	// yaml reports each error on its own line
+	msg := strings.Join(strings.Fields(err.Error()), " ")
+	l.log.Warnf("⚠️  Skipping %s in %s: %s\n", item, l.source, msg)
+}
+
+// report prints how many items were skipped, if any
+func (l *skipLog) report() {
+	if l.count > 0 {
+		l.log.Warnf("⚠️  Skipped %d malformed items in %s\n", l.count, l.source)
+	}
+}
+
+func parsePostmanCollection(filename string, log Logger) ([]APIRequest, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
@@ -59,31 +102,52 @@ func parsePostmanCollection(filename string) ([]APIRequest, error) {
 	}
 
 	requests := []APIRequest{}
+	skipped := &skipLog{source: "Postman collection", log: log}
 	
 	// Recursively parse items
 	for _, item := range collection.Item {
-		requests = append(requests, parseItems(item)...)
+		requests = append(requests, parseItems(item, "", skipped)...)
 	}
+	skipped.report()
 
 	return requests, nil
 }
 
-func parseItems(item PostmanItem) []APIRequest {
+func parseItems(item PostmanItem, folder string, skipped *skipLog) []APIRequest {
 	requests := []APIRequest{}
 
+	name := item.Name
+	if name == "" {
+		name = "(unnamed)"
+	}
+	if folder != "" {
+		name = folder + "/" + name
+	}
+	if item.invalid != nil {
+		skipped.skip(fmt.Sprintf("item %q", name), item.invalid)
+		return requests
+	}
+
 	// If it's a folder, recurse
 	if len(item.Item) > 0 {
 		for _, subItem := range item.Item {
-			requests = append(requests, parseItems(subItem)...)
+			requests = append(requests, parseItems(subItem, name, skipped)...)
 		}
 		return requests
 	}
 
 	// Parse single request
-	if item.Request.Method != "" {
-		headers := make(map[string]string)
+	switch {
+	case item.Item != nil:
+		// An empty folder
+	case item.Request == nil:
+		skipped.skip(fmt.Sprintf("item %q", name), fmt.Errorf("no request"))
+	case item.Request.Method == "" || item.Request.URL.Raw == "":
+		skipped.skip(fmt.Sprintf("item %q", name), fmt.Errorf("request has no method or URL"))
+	default:
+		headers := make(http.Header)
 		for _, h := range item.Request.Header {
-			headers[h.Key] = h.Value
+			headers.Add(h.Key, h.Value)
 		}
 
 		req := APIRequest{
@@ -133,6 +197,32 @@ type PathItem struct {
 	Delete  *Operation `json:"delete" yaml:"delete"`
 	Options *Operation `json:"options" yaml:"options"`
 	Head    *Operation `json:"head" yaml:"head"`
+
+	invalid error // why the path item couldn't be decoded, if it couldn't
+}
+
+// UnmarshalYAML and UnmarshalJSON keep a malformed path item in place, marked
+// invalid, so one bad path doesn't fail the whole spec
+func (p *PathItem) UnmarshalYAML(node *yaml.Node) error {
+	type path PathItem
+	var v path
+	if err := node.Decode(&v); err != nil {
+		*p = PathItem{invalid: err}
+		return nil
+	}
+	*p = PathItem(v)
+	return nil
+}
+
+func (p *PathItem) UnmarshalJSON(data []byte) error {
+	type path PathItem
+	var v path
+	if err := json.Unmarshal(data, &v); err != nil {
+		*p = PathItem{invalid: err}
+		return nil
+	}
+	*p = PathItem(v)
+	return nil
 }
 
 type Operation struct {
@@ -140,6 +230,32 @@ type Operation struct {
 	Summary     string       `json:"summary" yaml:"summary"`
 	Parameters  []Parameter  `json:"parameters" yaml:"parameters"`
 	RequestBody *RequestBody `json:"requestBody" yaml:"requestBody"`
+
+	invalid error // why the operation couldn't be decoded, if it couldn't
+}
+
+// UnmarshalYAML and UnmarshalJSON keep a malformed operation in place, marked
+// invalid, so the path's other operations are still parsed
+func (op *Operation) UnmarshalYAML(node *yaml.Node) error {
+	type operation Operation
+	var v operation
+	if err := node.Decode(&v); err != nil {
+		*op = Operation{invalid: err}
+		return nil
+	}
+	*op = Operation(v)
+	return nil
+}
+
+func (op *Operation) UnmarshalJSON(data []byte) error {
+	type operation Operation
+	var v operation
+	if err := json.Unmarshal(data, &v); err != nil {
+		*op = Operation{invalid: err}
+		return nil
+	}
+	*op = Operation(v)
+	return nil
 }
 
 type Parameter struct {
@@ -161,7 +277,10 @@ type MediaType struct {
 	Schema Schema `json:"schema" yaml:"schema"`
 }
 
-func parseOpenAPISpec(filename string) ([]APIRequest, error) {
+// parseOpenAPISpec turns each operation into a request template. Header,
+// query and cookie parameters become {name} placeholders; optional query and
+// cookie parameters are only included with includeOptional.
+func parseOpenAPISpec(filename string, includeOptional bool, log Logger) ([]APIRequest, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
@@ -190,8 +309,13 @@ func parseOpenAPISpec(filename string) ([]APIRequest, error) {
 	}
 
 	requests := []APIRequest{}
+	skipped := &skipLog{source: "OpenAPI spec", log: log}
 
 	for path, pathItem := range spec.Paths {
+		if pathItem.invalid != nil {
+			skipped.skip(fmt.Sprintf("path %s", path), pathItem.invalid)
+			continue
+		}
 		operations := map[string]*Operation{
 			"GET":     pathItem.Get,
 			"POST":    pathItem.Post,
@@ -206,6 +330,10 @@ func parseOpenAPISpec(filename string) ([]APIRequest, error) {
 			if op == nil {
 				continue
 			}
+			if op.invalid != nil {
+				skipped.skip(fmt.Sprintf("operation %s %s", method, path), op.invalid)
+				continue
+			}
 
 			// Convert path params from {id} format (already correct)
 			url := baseURL + path
@@ -213,20 +341,44 @@ func parseOpenAPISpec(filename string) ([]APIRequest, error) {
 			req := APIRequest{
 				Method:  method,
 				URL:     url,
-				Headers: make(map[string]string),
+				Headers: make(http.Header),
 				Params:  make(map[string]string),
 			}
 
 			// Extract parameters
+			query := []string{}
+			cookies := []string{}
 			for _, param := range op.Parameters {
-				if param.In == "header" {
-					req.Headers[param.Name] = fmt.Sprintf("{%s}", param.Name)
+				placeholder := fmt.Sprintf("{%s}", param.Name)
+				switch param.In {
+				case "header":
+					req.Headers.Set(param.Name, placeholder)
+				case "query", "cookie":
+					if !param.Required && !includeOptional {
+						continue
+					}
+					if param.In == "query" {
+						query = append(query, param.Name+"="+placeholder)
+					} else {
+						cookies = append(cookies, param.Name+"="+placeholder)
+					}
+				}
+			}
+			if len(query) > 0 {
+				sep := "?"
+				if strings.Contains(req.URL, "?") {
+					sep = "&"
 				}
+				req.URL += sep + strings.Join(query, "&")
+			}
+			if len(cookies) > 0 {
+				req.Headers.Set("Cookie", strings.Join(cookies, "; "))
 			}
 
 			requests = append(requests, req)
 		}
 	}
+	skipped.report()
 
 	return requests, nil
 }
@@ -245,7 +397,22 @@ type HARLog struct {
 }
 
 type HAREntry struct {
-	Request HARRequest `json:"request"`
+	Request *HARRequest `json:"request"`
+
+	invalid error // why the entry couldn't be decoded, if it couldn't
+}
+
+// UnmarshalJSON keeps a malformed entry in place, marked invalid, so one bad
+// entry doesn't fail the whole archive
+func (e *HAREntry) UnmarshalJSON(data []byte) error {
+	type entry HAREntry
+	var v entry
+	if err := json.Unmarshal(data, &v); err != nil {
+		*e = HAREntry{invalid: err}
+		return nil
+	}
+	*e = HAREntry(v)
+	return nil
 }
 
 type HARRequest struct {
@@ -265,7 +432,7 @@ type HARPostData struct {
 	Text     string `json:"text"`
 }
 
-func parseHARFile(filename string) ([]APIRequest, error) {
+func parseHARFile(filename string, log Logger) ([]APIRequest, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
@@ -278,15 +445,28 @@ func parseHARFile(filename string) ([]APIRequest, error) {
 
 	requests := []APIRequest{}
 	seen := make(map[string]bool) // Dedupe by method+URL
+	skipped := &skipLog{source: "HAR file", log: log}
+
+	for i, entry := range har.Log.Entries {
+		switch {
+		case entry.invalid != nil:
+			skipped.skip(fmt.Sprintf("entry %d", i+1), entry.invalid)
+			continue
+		case entry.Request == nil:
+			skipped.skip(fmt.Sprintf("entry %d", i+1), fmt.Errorf("no request"))
+			continue
+		case entry.Request.Method == "" || entry.Request.URL == "":
+			skipped.skip(fmt.Sprintf("entry %d", i+1), fmt.Errorf("request has no method or URL"))
+			continue
+		}
 
-	for _, entry := range har.Log.Entries {
 		key := entry.Request.Method + " " + entry.Request.URL
 		if seen[key] {
 			continue
 		}
 		seen[key] = true
 
-		headers := make(map[string]string)
+		headers := make(http.Header)
 		for _, h := range entry.Request.Headers {
 			// Skip pseudo-headers and common browser headers
 			lowerName := strings.ToLower(h.Name)
@@ -298,7 +478,7 @@ func parseHARFile(filename string) ([]APIRequest, error) {
 				lowerName == "user-agent" {
 				continue
 			}
-			headers[h.Name] = h.Value
+			headers.Add(h.Name, h.Value)
 		}
 
 		body := ""
@@ -316,6 +496,121 @@ func parseHARFile(filename string) ([]APIRequest, error) {
 
 		requests = append(requests, req)
 	}
+	skipped.report()
 
 	return requests, nil
 }
+
+// rawHTTPExtensions are the files --requests-dir picks up
+var rawHTTPExtensions = map[string]bool{".http": true, ".txt": true, ".req": true, "": true}
+
+// parseRequestsDir parses every raw HTTP request file in dir, skipping files
+// that don't look like a request
+func parseRequestsDir(dir string, log Logger) ([]APIRequest, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := []APIRequest{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !rawHTTPExtensions[strings.ToLower(filepath.Ext(name))] {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := parseRawHTTPRequest(data)
+		if err != nil {
+			log.Debugf("⚠️  Skipping %s: %v\n", name, err)
+			continue
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, nil
+}
+
+// parseRawHTTPRequest parses a request line, headers, a blank line and an
+// optional body. The request line may carry an absolute URL; otherwise the
+// Host header is used with https.
+func parseRawHTTPRequest(data []byte) (APIRequest, error) {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	head, body, _ := strings.Cut(text, "\n\n")
+	lines := strings.Split(head, "\n")
+
+	// Leading blank and comment lines (# or //) are common in .http files
+	for len(lines) > 0 {
+		l := strings.TrimSpace(lines[0])
+		if l != "" && !strings.HasPrefix(l, "#") && !strings.HasPrefix(l, "//") {
+			break
+		}
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return APIRequest{}, fmt.Errorf("no request line")
+	}
+
+	fields := strings.Fields(lines[0])
+	if len(fields) < 2 || len(fields) > 3 || !isHTTPMethod(fields[0]) {
+		return APIRequest{}, fmt.Errorf("not an HTTP request line: %q", lines[0])
+	}
+	if len(fields) == 3 && !strings.HasPrefix(fields[2], "HTTP/") {
+		return APIRequest{}, fmt.Errorf("bad HTTP version %q", fields[2])
+	}
+	method, target := fields[0], fields[1]
+
+	headers := make(http.Header)
+	host := ""
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return APIRequest{}, fmt.Errorf("malformed header line: %q", line)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		// Host is folded into the URL; Content-Length is recomputed on send
+		switch strings.ToLower(name) {
+		case "host":
+			host = value
+			continue
+		case "content-length":
+			continue
+		}
+		headers.Add(name, value)
+	}
+
+	url := target
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		if host == "" {
+			return APIRequest{}, fmt.Errorf("relative request target %q without a Host header", target)
+		}
+		url = "https://" + host + target
+	}
+
+	return APIRequest{
+		Method:  method,
+		URL:     url,
+		Headers: headers,
+		Body:    strings.TrimRight(body, "\n"),
+		Params:  make(map[string]string),
+	}, nil
+}
+
+func isHTTPMethod(m string) bool {
+	switch m {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTemp writes content to a file in a test temp dir and returns its path
+func writeTemp(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDuplicateHeadersRoundTrip(t *testing.T) {
+	har := writeTemp(t, "dup.har", `{"log":{"entries":[{"request":{
+		"method":"GET","url":"https://api.example.com/users/123",
+		"headers":[
+			{"name":"X-Forwarded-For","value":"10.0.0.1"},
+			{"name":"X-Forwarded-For","value":"10.0.0.2"},
+			{"name":"Accept","value":"application/json"}
+		]}}]}}`)
+	collection := writeTemp(t, "dup.json", `{"item":[{"name":"get","request":{
+		"method":"GET","url":{"raw":"https://api.example.com/users/123"},
+		"header":[
+			{"key":"X-Forwarded-For","value":"10.0.0.1"},
+			{"key":"X-Forwarded-For","value":"10.0.0.2"}
+		]}}]}`)
+
+	parsers := map[string]func() ([]APIRequest, error){
+		"har":     func() ([]APIRequest, error) { return parseHARFile(har, nopLogger{}) },
+		"postman": func() ([]APIRequest, error) { return parsePostmanCollection(collection, nopLogger{}) },
+	}
+	want := []string{"10.0.0.1", "10.0.0.2"}
+	for name, parse := range parsers {
+		requests, err := parse()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(requests) != 1 {
+			t.Fatalf("%s: got %d requests, want 1", name, len(requests))
+		}
+		if got := requests[0].Headers.Values("X-Forwarded-For"); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: parsed X-Forwarded-For = %v, want %v", name, got, want)
+		}
+
+		s := NewScanner(nil, requests)
+		user := User{Name: "alice", Headers: map[string]string{"Authorization": "Bearer a"}}
+		httpReq := s.buildRequest(requests[0], user, nil)
+		if got := httpReq.Header.Values("X-Forwarded-For"); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: sent X-Forwarded-For = %v, want %v", name, got, want)
+		}
+		if got := httpReq.Header.Get("Authorization"); got != "Bearer a" {
+			t.Errorf("%s: sent Authorization = %q, want the user's", name, got)
+		}
+	}
+}
+
+func TestUserHeaderReplacesAllDuplicates(t *testing.T) {
+	req := APIRequest{
+		Method:  "GET",
+		URL:     "https://api.example.com/users/123",
+		Headers: map[string][]string{"X-Tenant": {"a", "b"}},
+	}
+	user := User{Name: "alice", Headers: map[string]string{"x-tenant": "mine"}}
+	httpReq := NewScanner(nil, nil).buildRequest(req, user, nil)
+	if got := httpReq.Header.Values("X-Tenant"); !reflect.DeepEqual(got, []string{"mine"}) {
+		t.Errorf("X-Tenant = %v, want only the user's value", got)
+	}
+}
+
+// Skipped entries are reported through the logger, not straight to stderr
+func TestSkippedEntriesGoToLogger(t *testing.T) {
+	har := writeTemp(t, "bad.har", `{"log":{"entries":[
+		{"request":{"method":"GET","url":"http://api.test/users/1","headers":[]}},
+		{"request":"oops"}
+	]}}`)
+
+	log := &recordingLogger{}
+	requests, err := parseHARFile(har, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("parsed %d requests, want 1", len(requests))
+	}
+	warns := log.warnings()
+	if len(warns) != 2 || !strings.Contains(warns[0], "Skipping entry") || !strings.Contains(warns[1], "Skipped 1 malformed items in HAR file") {
+		t.Errorf("warnings = %q", warns)
+	}
+}
+
+// A malformed Postman item or OpenAPI operation is skipped with a warning;
+// the rest of the file still imports
+func TestMalformedItemsSkipped(t *testing.T) {
+	postman := writeTemp(t, "bad.postman.json", `{"item":[
+		{"name":"ok","request":{"method":"GET","url":{"raw":"http://api.test/users/1"}}},
+		{"name":"broken","request":{"method":42}}
+	]}`)
+	openapi := writeTemp(t, "bad.openapi.yaml", `openapi: 3.0.0
+servers:
+  - url: http://api.test
+paths:
+  /users/{id}:
+    get:
+      summary: ok
+    delete: oops
+  /orders: [not, a, path]
+`)
+
+	for _, tc := range []struct {
+		name    string
+		parse   func(Logger) ([]APIRequest, error)
+		summary string
+	}{
+		{"postman", func(log Logger) ([]APIRequest, error) { return parsePostmanCollection(postman, log) }, "Skipped 1 malformed items in Postman collection"},
+		{"openapi", func(log Logger) ([]APIRequest, error) { return parseOpenAPISpec(openapi, false, log) }, "Skipped 2 malformed items in OpenAPI spec"},
+	} {
+		name := tc.name
+		log := &recordingLogger{}
+		requests, err := tc.parse(log)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(requests) != 1 || requests[0].Method != "GET" {
+			t.Errorf("%s: requests = %+v, want the one GET", name, requests)
+		}
+		warns := log.warnings()
+		if len(warns) == 0 || !strings.Contains(warns[len(warns)-1], tc.summary) {
+			t.Errorf("%s: warnings = %q", name, warns)
+		}
+	}
+
+	// a file that doesn't decode at all still fails
+	if _, err := parsePostmanCollection(writeTemp(t, "junk.json", `[1,2`), &recordingLogger{}); err == nil {
+		t.Error("undecodable collection accepted")
+	}
+}
+
+// An OpenAPI operation with parameters in every location: the resource-naming
+// ones are filled for the target, the cookie for whoever sends the request
+func TestOpenAPIMixedParameterLocations(t *testing.T) {
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":%q,"email":"%s@example.com"}`, r.URL.Path, r.Header.Get("X-Tenant"))
+	}))
+	spec := writeTemp(t, "spec.json", `{
+		"openapi": "3.0.0",
+		"servers": [{"url": "`+srv.URL+`"}],
+		"paths": {"/users/{user_id}": {"get": {"parameters": [
+			{"name": "user_id", "in": "path", "required": true},
+			{"name": "fields", "in": "query", "required": true},
+			{"name": "debug", "in": "query"},
+			{"name": "X-Tenant", "in": "header"},
+			{"name": "session", "in": "cookie", "required": true}
+		]}}}
+	}`)
+
+	requests, err := parseOpenAPISpec(spec, false, nopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("parsed %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if want := srv.URL + "/users/{user_id}?fields={fields}"; req.URL != want {
+		t.Errorf("URL = %q, want %q", req.URL, want)
+	}
+	if got := req.Headers.Get("X-Tenant"); got != "{X-Tenant}" {
+		t.Errorf("X-Tenant = %q", got)
+	}
+	if got := req.Headers.Get("Cookie"); got != "session={session}" {
+		t.Errorf("Cookie = %q", got)
+	}
+
+	user := func(name, id string) User {
+		return User{
+			Name:    name,
+			Headers: map[string]string{"Authorization": "Bearer " + name},
+			Cookies: map[string]string{"theme": "dark"},
+			Params:  map[string]string{"user_id": id, "fields": "email", "X-Tenant": "t-" + name, "session": "s-" + name},
+		}
+	}
+	users := []User{user("alice", "123"), user("bob", "456")}
+	owner := map[string]string{"/users/123": "alice", "/users/456": "bob"}
+
+	if _, err := fastScanner(users, requests).Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	seen := srv.requests()
+	if len(seen) == 0 {
+		t.Fatal("no requests sent")
+	}
+	crossed := 0
+	for _, r := range seen {
+		target := owner[r.Path]
+		if target == "" || r.Query != "fields=email" {
+			t.Errorf("sent %s?%s", r.Path, r.Query)
+			continue
+		}
+		if got := r.Header.Get("X-Tenant"); got != "t-"+target {
+			t.Errorf("%s: X-Tenant = %q, want the target's", r.Path, got)
+		}
+		sender := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		want := ""
+		if sender != "" {
+			want = "session=s-" + sender + "; theme=dark"
+		}
+		if sender != "" && sender != target {
+			crossed++
+		}
+		if got := r.Header.Get("Cookie"); got != want {
+			t.Errorf("%s as %q: Cookie = %q, want %q", r.Path, sender, got, want)
+		}
+	}
+	if crossed == 0 {
+		t.Error("no cross-user request was sent")
+	}
+}
+
+// Raw request files: relative targets take their Host, comments and CRLF are
+// tolerated, and hidden, foreign and unparseable files are skipped
+func TestParseRequestsDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"get.http":    "# fetch a profile\r\nGET /api/users/123 HTTP/1.1\r\nHost: api.example.com\r\nAuthorization: Bearer x\r\n\r\n",
+		"create.req":  "POST https://api.example.com/api/orders\nContent-Type: application/json\nContent-Length: 14\n\n{\"item\":\"pen\"}\n",
+		"bad.txt":     "not a request\n",
+		".draft.http": "GET /hidden HTTP/1.1\nHost: api.example.com\n",
+		"notes.md":    "GET /not-a-request-file HTTP/1.1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	requests, err := parseRequestsDir(dir, nopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("parsed %d requests, want 2: %+v", len(requests), requests)
+	}
+
+	// os.ReadDir sorts by name: create.req, then get.http
+	create, get := requests[0], requests[1]
+	if create.Method != "POST" || create.URL != "https://api.example.com/api/orders" || create.Body != `{"item":"pen"}` {
+		t.Errorf("create.req = %+v", create)
+	}
+	if create.Headers.Get("Content-Length") != "" {
+		t.Error("Content-Length kept; it is recomputed on send")
+	}
+	if get.Method != "GET" || get.URL != "https://api.example.com/api/users/123" || get.Headers.Get("Authorization") != "Bearer x" {
+		t.Errorf("get.http = %+v", get)
+	}
+}
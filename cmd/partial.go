@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// piiKeywords mark JSON keys whose values identify a person; together with
+// secretKeywords they decide which baseline fields are checked for partial
+// exposure
+var piiKeywords = []string{"email", "phone", "mobile", "ssn", "address", "birth", "dob", "iban", "card", "account_number", "tax", "passport"}
+
+// minLeakValueLen skips values too short to be telling when found in a response
+const minLeakValueLen = 4
+
+// sensitiveFields returns the person-identifying and secret leaf values of a
+// JSON document, by path
+func sensitiveFields(body []byte) map[string]string {
+	fields, ok := flattenJSON(body)
+	if !ok {
+		return nil
+	}
+
+	sensitive := make(map[string]string)
+	for path, raw := range fields {
+		value := strings.Trim(raw, `"`)
+		if len(value) < minLeakValueLen || raw == "null" {
+			continue
+		}
+		if isSensitiveKey(leafKey(path)) {
+			sensitive[path] = value
+		}
+	}
+	if len(sensitive) == 0 {
+		return nil
+	}
+	return sensitive
+}
+
+func isSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, kw := range piiKeywords {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	for _, kw := range secretKeywords {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// leakedFields returns the paths of the victim's sensitive fields whose values
+// appear anywhere in body, skipping values the attacker also has in their own
+// response (a shared support email is not a leak)
+func leakedFields(victim, own map[string]string, body []byte) []string {
+	if len(victim) == 0 {
+		return nil
+	}
+
+	ownValues := make(map[string]bool, len(own))
+	for _, v := range own {
+		ownValues[v] = true
+	}
+
+	got := map[string]bool{}
+	if fields, ok := flattenJSON(body); ok {
+		for _, raw := range fields {
+			got[strings.Trim(raw, `"`)] = true
+		}
+	}
+
+	text := string(body)
+	leaked := []string{}
+	for path, value := range victim {
+		if ownValues[value] {
+			continue
+		}
+		// Exact leaf matches, or the value embedded in a larger string or a non-JSON body
+		if got[value] || strings.Contains(text, value) {
+			leaked = append(leaked, path)
+		}
+	}
+	sort.Strings(leaked)
+	return leaked
+}
+
+// describePartialExposure words the finding for a response that differs from
+// the victim's baseline but still carries some of their sensitive values
+func describePartialExposure(attacker, victim User, status int, leaked []string) (string, string) {
+	description := fmt.Sprintf("Partial data exposure: %s got %d of '%s's sensitive fields in an otherwise different response",
+		attackerLabel(attacker), len(leaked), victim.Name)
+	evidence := fmt.Sprintf("Status: %d, victim values present for: %s", status, summarizeIDs(leaked))
+	return description, evidence
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSensitiveFields(t *testing.T) {
+	got := sensitiveFields([]byte(`{"name":"Bob","contact":{"email":"bob@example.com","phone":"555"},"api_key":"k-123456","dob":null}`))
+	if len(got) != 2 || got["$.contact.email"] != "bob@example.com" || got["$.api_key"] != "k-123456" {
+		t.Errorf("sensitiveFields = %v, want email and api_key (short and null values skipped)", got)
+	}
+}
+
+func TestLeakedFields(t *testing.T) {
+	victim := map[string]string{"$.email": "bob@example.com", "$.support": "help@example.com", "$.iban": "DE89370400440532013000"}
+	own := map[string]string{"$.support": "help@example.com"}
+	body := []byte(`{"profile":{"owner":"alice"},"note":"forwarded from bob@example.com","iban":"DE89370400440532013000"}`)
+
+	if got := leakedFields(victim, own, body); strings.Join(got, ",") != "$.email,$.iban" {
+		t.Errorf("leakedFields = %q, want email and iban but not the shared support address", got)
+	}
+}
+
+// A response that is neither the victim's nor theirs in size, but carries
+// some of the victim's sensitive values, is a partial exposure
+func TestPartialExposureFinding(t *testing.T) {
+	emails := map[string]string{"123": "alice@example.com", "456": "bob@example.com"}
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/api/users/")
+		if (id == "123") == (r.Header.Get("Authorization") == "Bearer alice-token") {
+			fmt.Fprintf(w, `{"id":%q,"email":%q,"bio":"%s"}`, id, emails[id], strings.Repeat("x", 300))
+			return
+		}
+		// Someone else's profile: a short public card that still leaks the email
+		fmt.Fprintf(w, `{"contact":%q}`, emails[id])
+	}))
+	s := fastScanner(selftestUsers(), []APIRequest{getRequest(srv.URL + "/api/users/{user_id}")})
+
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want one per pair: %+v", len(findings), findings)
+	}
+	for _, f := range findings {
+		if f.Kind != kindPartialExposure || f.Severity != SeverityMedium || !strings.Contains(f.Evidence, "$.email") {
+			t.Errorf("finding = %+v", f)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	replayFindings string
+	replayUsers    string
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <finding-id>",
+	Short: "Re-send the request behind a saved finding and check if it still reproduces",
+	Long: `Replay loads a findings file written with --format json or jsonl, re-sends
+the exact request recorded for the given finding ID (or a unique prefix of
+it), and compares the response with the one saved. It exits 0 only when the
+finding no longer reproduces, so it can gate a remediation check: 1 when it
+still does (or needs a manual look), 2 when the result is inconclusive.
+
+Recorded credentials may have expired since the scan. Pass the users file
+with --users to send the attacker's current credentials instead.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runReplay,
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&replayFindings, "findings", "", "Findings file from a previous scan (json or jsonl)")
+	replayCmd.Flags().StringVarP(&replayUsers, "users", "u", "", "User contexts file; re-applies the attacker's current credentials")
+	addNetworkFlags(replayCmd)
+	replayCmd.MarkFlagRequired("findings")
+	rootCmd.AddCommand(replayCmd)
+}
+
+// Finding kinds name what a test probed. Unlike descriptions they carry no
+// response data (counts, statuses), so they can go into the finding ID.
+const (
+	kindCrossUser       = "cross_user"
+	kindCrossUserList   = "cross_user_list"
+	kindCrossUserWrite  = "cross_user_write"
+	kindPartialExposure = "partial_exposure"
+	kindNoAuth          = "no_auth"
+	kindRequestError    = "request_error"
+	kindLargeResponse   = "large_response"
+	kindEnumeration     = "enumeration"
+	kindSiblings        = "siblings"
+	kindTypeConfusion   = "type_confusion"
+)
+
+// findingKind qualifies a kind with what it was probed on (an ID key), for
+// tests that report once per key
+func findingKind(kind string, on ...string) string {
+	return strings.Join(append([]string{kind}, on...), ":")
+}
+
+// findingID derives a stable identifier from what a finding is about (its
+// kind, request and users), so the same issue gets the same ID across scans.
+// Findings saved before kinds were recorded hash their description instead.
+func findingID(f Finding) string {
+	what := f.Kind
+	if what == "" {
+		what = f.Description
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		f.Method, f.Endpoint, what, f.Attacker, f.Victim, f.Credential,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:6])
+}
+
+// loadFindings reads a JSON report ({"findings": [...]}), a bare JSON array,
+// or JSONL
+func loadFindings(path string) ([]Finding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+
+	var report struct {
+		Findings []Finding `json:"findings"`
+	}
+	if len(data) > 0 && data[0] == '{' && json.Unmarshal(data, &report) == nil && report.Findings != nil {
+		return report.Findings, nil
+	}
+
+	var list []Finding
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxInputSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var f Finding
+		if err := json.Unmarshal(text, &f); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		list = append(list, f)
+	}
+	return list, scanner.Err()
+}
+
+// lookupFinding finds the finding with the given ID or unique ID prefix.
+// Findings saved before IDs were recorded get theirs computed.
+func lookupFinding(findings []Finding, id string) (Finding, error) {
+	matches := []Finding{}
+	for _, f := range findings {
+		if f.ID == "" {
+			f.ID = findingID(f)
+		}
+		if f.ID == id {
+			return f, nil
+		}
+		if strings.HasPrefix(f.ID, id) {
+			matches = append(matches, f)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Finding{}, fmt.Errorf("no finding with ID %s", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return Finding{}, fmt.Errorf("ID prefix %s matches %d findings", id, len(matches))
+	}
+}
+
+// replayRequest rebuilds the recorded request
+func replayRequest(rec *RecordedRequest) (*http.Request, error) {
+	req, err := http.NewRequest(rec.Method, rec.URL, strings.NewReader(rec.Body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = rec.Headers.Clone()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	return req, nil
+}
+
+// replayUser finds the finding's attacker, with the credential set it used,
+// among users
+func replayUser(users []User, f Finding) (User, error) {
+	for _, u := range normalizeUsers(users) {
+		if u.Name != f.Attacker {
+			continue
+		}
+		for _, set := range credentialContexts(u) {
+			if set.Label == f.Credential {
+				u.Headers = set.Headers
+				u.credential = set.Label
+				return u, nil
+			}
+		}
+		return User{}, fmt.Errorf("user '%s' has no credential set '%s'", u.Name, f.Credential)
+	}
+	return User{}, fmt.Errorf("no user '%s' in the users file", f.Attacker)
+}
+
+// reapplyCredentials swaps the recorded credentials on req for user's current
+// ones: headers, cookies and query-string auth params. The scan-wide headers
+// and gateway auth (see applyGlobalHeaders) go back on too, since the recorded
+// ones were stripped with the user's.
+func (s *Scanner) reapplyCredentials(req *http.Request, user User) {
+	for key := range req.Header {
+		if isAuthName(key) {
+			req.Header.Del(key)
+		}
+	}
+	for key, val := range user.Headers {
+		req.Header.Set(key, val)
+	}
+	s.applyGlobalHeaders(req, user.Headers)
+	applyCookies(req, user.Headers, user.Cookies, nil)
+	applyAuthParams(req, user.AuthParams)
+}
+
+// sameSnippet reports whether body matches a response snippet saved by withExchange
+func sameSnippet(snippet string, body []byte) bool {
+	if len(body) > maxSnippetSize {
+		return snippet == string(body[:maxSnippetSize])+"..."
+	}
+	return snippet == string(body)
+}
+
+func runReplay(cmd *cobra.Command, args []string) {
+	findings, err := loadFindings(replayFindings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading findings: %v\n", err)
+		os.Exit(1)
+	}
+
+	f, err := lookupFinding(findings, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if f.Request == nil {
+		fmt.Fprintf(os.Stderr, "Error: finding %s has no recorded request to replay\n", f.ID)
+		os.Exit(1)
+	}
+
+	req, err := replayRequest(f.Request)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error rebuilding request: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Without --users the request goes out with the credentials as recorded
+	attacker := User{}
+	var users []User
+	if replayUsers != "" && f.Attacker != "" && !f.Anonymous {
+		users, err = loadUsers(replayUsers)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading users: %v\n", err)
+			os.Exit(1)
+		}
+		attacker, err = replayUser(users, f)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Same proxy, protocol, gateway auth and shared headers as the scan
+	scanner := NewScanner(nil, nil)
+	if err := configureNetwork(scanner, users); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	refreshed := false
+	if len(users) > 0 {
+		scanner.reapplyCredentials(req, attacker)
+		refreshed = true
+	}
+
+	masked := make(map[string]bool)
+	for _, name := range f.Request.AuthQuery {
+		masked[strings.ToLower(name)] = true
+	}
+	for name := range attacker.AuthParams {
+		masked[strings.ToLower(name)] = true
+	}
+	fmt.Printf("🔁 Replaying %s [%s] %s %s\n", f.ID, f.Severity.Label(), req.Method, redactQuery(req.URL.String(), masked))
+	fmt.Printf("   %s\n", f.Description)
+	if isWriteMethod(req.Method) {
+		fmt.Printf("   ⚠️  %s is a write; the request is sent again as recorded\n", req.Method)
+	}
+	fmt.Println()
+
+	resp, err := scanner.executeAs(attacker, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: request failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer resp.Body.Close()
+	body, _ := scanner.readBody(resp)
+
+	msg, code := replayVerdict(f, resp.StatusCode, body, refreshed)
+	fmt.Println(msg)
+	if code != 0 {
+		os.Exit(code)
+	}
+}
+
+// replayVerdict judges a replayed finding's response: exit code 0 when it no
+// longer reproduces, 1 when it does or needs a manual look, 2 when a refusal
+// may only mean the attacker's credentials expired. refreshed reports that
+// --users re-applied current credentials, which makes a 403 a real denial.
+func replayVerdict(f Finding, status int, body []byte, refreshed bool) (string, int) {
+	authenticated := f.Attacker != "" && !f.Anonymous
+	switch {
+	case authenticated && (status == http.StatusUnauthorized || status == http.StatusForbidden && !refreshed):
+		msg := fmt.Sprintf("⚠️  Inconclusive: got %d; the attacker's credentials may no longer be valid", status)
+		if !refreshed {
+			msg += " (pass --users to send current ones)"
+		}
+		return msg, 2
+	case status < 200 || status > 299:
+		return fmt.Sprintf("✅ No longer reproduces: got %d", status), 0
+	case sameSnippet(f.Response, body):
+		return fmt.Sprintf("🔴 Still reproduces: got %d with the same response", status), 1
+	default:
+		return fmt.Sprintf("🟠 Got %d but the response changed (%d bytes); verify manually", status, len(body)), 1
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// IDs must not change when only the response data in a description does
+func TestFindingIDIgnoresLiveData(t *testing.T) {
+	s := fastScanner(nil, nil)
+	req := getRequest("http://api.test/users/456/orders")
+	alice, bob := User{Name: "alice", Headers: map[string]string{"Authorization": "a"}}, User{Name: "bob"}
+	victim := Baseline{StatusCode: 200, BodySize: 30, BodyHash: "v", ItemIDs: []string{"1", "2", "3"}}
+
+	first := s.crossUserFinding(req, alice, bob, 200, []byte(`[{"id":"1"}]`), false, victim, Baseline{})
+	second := s.crossUserFinding(req, alice, bob, 200, []byte(`[{"id":"1"},{"id":"2"}]`), false, victim, Baseline{})
+	if first == nil || second == nil {
+		t.Fatal("expected list findings for both scans")
+	}
+	if first.Description == second.Description {
+		t.Fatalf("descriptions should differ for this test: %q", first.Description)
+	}
+	if findingID(*first) != findingID(*second) {
+		t.Errorf("IDs differ across scans: %s vs %s", findingID(*first), findingID(*second))
+	}
+
+	other := *first
+	other.Attacker = "carol"
+	if findingID(other) == findingID(*first) {
+		t.Error("findings for different attackers share an ID")
+	}
+
+	// Reports saved before kinds existed keep their description-based IDs
+	legacy := *first
+	legacy.Kind = ""
+	if findingID(legacy) == findingID(*first) {
+		t.Error("a finding without a kind should hash its description")
+	}
+}
+
+func TestReplayReappliesCredentials(t *testing.T) {
+	users := []User{{
+		Name:       "alice",
+		Headers:    map[string]string{"Authorization": "Bearer fresh"},
+		Cookies:    map[string]string{"sid": "new"},
+		AuthParams: map[string]string{"api_key": "k2"},
+		Credentials: []CredentialSet{
+			{Label: "admin", Headers: map[string]string{"Authorization": "Bearer fresh-admin"}},
+		},
+	}}
+
+	for label, want := range map[string]string{"": "Bearer fresh", "admin": "Bearer fresh-admin"} {
+		user, err := replayUser(users, Finding{Attacker: "alice", Credential: label})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := replayRequest(&RecordedRequest{
+			Method:  "GET",
+			URL:     "http://api.test/users/456?api_key=k1&page=2",
+			Headers: http.Header{"Authorization": {"Bearer expired"}, "Cookie": {"sid=old"}, "Accept": {"application/json"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		NewScanner(nil, nil).reapplyCredentials(req, user)
+
+		if got := req.Header.Get("Authorization"); got != want {
+			t.Errorf("credential %q: Authorization = %q, want %q", label, got, want)
+		}
+		if got := req.Header.Get("Cookie"); got != "sid=new" {
+			t.Errorf("Cookie = %q", got)
+		}
+		if got := req.Header.Get("Accept"); got != "application/json" {
+			t.Errorf("Accept = %q", got)
+		}
+		if got := req.URL.RawQuery; got != "page=2&api_key=k2" {
+			t.Errorf("query = %q", got)
+		}
+	}
+
+	if _, err := replayUser(users, Finding{Attacker: "mallory"}); err == nil {
+		t.Error("replayUser found a user that isn't in the file")
+	}
+}
+
+func TestReplayVerdict(t *testing.T) {
+	attacked := Finding{Attacker: "alice", Victim: "bob", Response: "secret"}
+	noAuth := Finding{Victim: "bob", Response: "secret"}
+
+	tests := []struct {
+		name      string
+		f         Finding
+		status    int
+		body      string
+		refreshed bool
+		want      int
+	}{
+		{"fixed", attacked, 404, "", false, 0},
+		{"still reproduces", attacked, 200, "secret", false, 1},
+		{"changed", attacked, 200, "other", false, 1},
+		{"401 with recorded credentials", attacked, 401, "", false, 2},
+		{"403 with recorded credentials", attacked, 403, "", false, 2},
+		{"401 with current credentials", attacked, 401, "", true, 2},
+		{"403 with current credentials", attacked, 403, "", true, 0},
+		{"no-auth finding now refused", noAuth, 401, "", false, 0},
+	}
+	for _, tt := range tests {
+		if _, code := replayVerdict(tt.f, tt.status, []byte(tt.body), tt.refreshed); code != tt.want {
+			t.Errorf("%s: exit code %d, want %d", tt.name, code, tt.want)
+		}
+	}
+}
+
+// Behind an edge gateway the refreshed request must still carry the gateway's
+// basic auth, or the gateway's 401 reads as "fixed"
+func TestReplayKeepsGatewayAuth(t *testing.T) {
+	const gateway = "Basic ZWRnZTpwdw==" // edge:pw
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != gateway {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"key":%q}`, r.Header.Get("X-API-Key"))
+	}))
+
+	user := User{Name: "alice", Headers: map[string]string{"X-API-Key": "fresh"}}
+	req, err := replayRequest(&RecordedRequest{
+		Method:  "GET",
+		URL:     srv.URL + "/users/456",
+		Headers: http.Header{"Authorization": {gateway}, "X-Api-Key": {"expired"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewScanner(nil, nil)
+	s.SetGatewayBasicAuth("edge", "pw")
+	s.SetGlobalHeaders(http.Header{"X-Tenant": {"acme"}})
+	s.reapplyCredentials(req, user)
+	resp, err := s.executeAs(user, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("gateway answered %d", resp.StatusCode)
+	}
+	got := srv.requests()[0].Header
+	if got.Get("X-API-Key") != "fresh" || got.Get("X-Tenant") != "acme" {
+		t.Errorf("sent headers %v", got)
+	}
+}
+
+func TestReplayHasNetworkFlags(t *testing.T) {
+	for _, name := range []string{"proxy", "proxy-user", "proxy-pass", "http2", "h2c", "timeout", "header", "basic-auth"} {
+		if replayCmd.Flags().Lookup(name) == nil {
+			t.Errorf("replay has no --%s flag", name)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// requestErrorFinding records a test request that never got a response, so
+// an unreachable endpoint isn't mistaken for one that correctly denied
+// access. attacker and victim are empty for the no-auth test. It returns nil
+// when the request was abandoned because the scan is stopping.
+func requestErrorFinding(req APIRequest, attacker, victim string, err error) *Finding {
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+
+	class := errorClass(err)
+	return &Finding{
+		Kind:        kindRequestError,
+		Severity:    SeverityInfo,
+		Endpoint:    req.URL,
+		Method:      req.Method,
+		Description: fmt.Sprintf("Request failed (%s); endpoint was not tested", class),
+		Evidence:    err.Error(),
+		Timestamp:   time.Now(),
+		Attacker:    attacker,
+		Victim:      victim,
+		ErrorClass:  class,
+	}
+}
+
+// errorClass names the kind of transport failure behind err
+func errorClass(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &dnsErr):
+		return "dns"
+	case isTLSError(err):
+		return "tls"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection_refused"
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return "connection_reset"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	default:
+		return "request_error"
+	}
+}
+
+func isTLSError(err error) bool {
+	var recordErr tls.RecordHeaderError
+	var verifyErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &recordErr) || errors.As(err, &verifyErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostErr) || errors.As(err, &invalidErr) {
+		return true
+	}
+	// Handshake alerts are unexported types, and net/http flattens a plain
+	// HTTP reply on a TLS connection into a string error
+	msg := err.Error()
+	return strings.Contains(msg, "tls: ") || strings.Contains(msg, "HTTP response to HTTPS client")
+}
+
+// seenRequestError reports whether an error finding of the same class was
+// already recorded for f's endpoint, so one dead host doesn't produce a
+// finding per user pair
+func (s *Scanner) seenRequestError(f Finding) bool {
+	if s.requestErrors == nil {
+		s.requestErrors = make(map[string]bool)
+	}
+	key := f.Method + " " + f.Endpoint + " " + f.ErrorClass
+	if s.requestErrors[key] {
+		return true
+	}
+	s.requestErrors[key] = true
+	return false
+}
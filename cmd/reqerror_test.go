@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// A cross-user request that never gets a response yields one INFO finding
+// per endpoint and failure class, not a finding per user pair, and never a
+// clean bill of health
+func TestRequestErrorsBecomeInfoFindings(t *testing.T) {
+	inner := selftestHandler()
+	owners := map[string]string{"/api/users/123": "Bearer alice-token", "/api/users/456": "Bearer bob-token"}
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Baselines are answered; anything else has its connection dropped
+		if owners[r.URL.Path] != r.Header.Get("Authorization") {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		inner.ServeHTTP(w, r)
+	}))
+
+	for _, workers := range []int{1, 2} {
+		s := fastScanner(selftestUsers(), []APIRequest{getRequest(srv.URL + "/api/users/{user_id}")})
+		s.SetWorkers(workers)
+		findings, err := s.Scan(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(findings) != 1 {
+			t.Fatalf("workers=%d: got %d findings, want one: %+v", workers, len(findings), findings)
+		}
+		f := findings[0]
+		if f.Kind != kindRequestError || f.Severity != SeverityInfo || f.ErrorClass != "connection_reset" {
+			t.Errorf("workers=%d: finding = %+v", workers, f)
+		}
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	tlsSrv := httptest.NewUnstartedServer(selftestHandler())
+	tlsSrv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	tlsSrv.StartTLS() // with a certificate the client doesn't trust
+	defer tlsSrv.Close()
+	if _, err := http.Get(tlsSrv.URL); err == nil || errorClass(err) != "tls" {
+		t.Errorf("untrusted certificate: errorClass(%v) = %q, want tls", err, errorClass(err))
+	}
+
+	if _, err := http.Get("http://no-such-host.invalid/"); err != nil && errorClass(err) != "dns" {
+		t.Errorf("errorClass(%v) = %q, want dns", err, errorClass(err))
+	}
+	if class := errorClass(context.DeadlineExceeded); class != "timeout" {
+		t.Errorf("errorClass(DeadlineExceeded) = %q, want timeout", class)
+	}
+	if f := requestErrorFinding(APIRequest{}, "", "", context.Canceled); f != nil {
+		t.Errorf("a cancelled request became a finding: %+v", f)
+	}
+}
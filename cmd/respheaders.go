@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// defaultEvidenceHeaders are the response headers kept on baselines and
+// findings: enough to tell whether a response was JSON, how big it claimed
+// to be and whether a cache served it
+var defaultEvidenceHeaders = []string{"Content-Type", "Content-Length", "Cache-Control"}
+
+// maxEvidenceHeaders caps how many headers SetEvidenceHeaders accepts, and
+// maxHeaderValueSize how much of each value is kept, so findings stay small
+const (
+	maxEvidenceHeaders = 10
+	maxHeaderValueSize = 256
+)
+
+// SetEvidenceHeaders sets the response headers recorded on baselines and
+// cross-user findings (none disables them)
+func (s *Scanner) SetEvidenceHeaders(names []string) error {
+	if len(names) > maxEvidenceHeaders {
+		return fmt.Errorf("at most %d evidence headers can be kept, got %d", maxEvidenceHeaders, len(names))
+	}
+	canonical := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		canonical = append(canonical, http.CanonicalHeaderKey(name))
+	}
+	s.evidenceHeaders = canonical
+	return nil
+}
+
+// keptHeaders returns the configured evidence headers present on resp
+func (s *Scanner) keptHeaders(resp *http.Response) map[string]string {
+	if len(s.evidenceHeaders) == 0 {
+		return nil
+	}
+	kept := make(map[string]string, len(s.evidenceHeaders))
+	for _, name := range s.evidenceHeaders {
+		val := resp.Header.Get(name)
+		// Use the length the transport parsed when the header map lacks it
+		if val == "" && name == "Content-Length" && resp.ContentLength >= 0 {
+			val = strconv.FormatInt(resp.ContentLength, 10)
+		}
+		if val == "" {
+			continue
+		}
+		if len(val) > maxHeaderValueSize {
+			val = val[:maxHeaderValueSize] + "..."
+		}
+		kept[name] = val
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+// attachHeaders records the attacker's response headers on f next to those
+// of the baseline it was compared against
+func (s *Scanner) attachHeaders(f *Finding, resp *http.Response, baseline Baseline) {
+	f.ResponseHeaders = s.keptHeaders(resp)
+	f.BaselineHeaders = baseline.Headers
+}
+
+// headerRow is one line of a finding's header comparison
+type headerRow struct {
+	Name     string
+	Response string
+	Baseline string
+}
+
+// headerRows lines up a finding's response and baseline headers by name
+func headerRows(f Finding) []headerRow {
+	names := []string{}
+	for name := range f.ResponseHeaders {
+		names = append(names, name)
+	}
+	for name := range f.BaselineHeaders {
+		if _, ok := f.ResponseHeaders[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	rows := make([]headerRow, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, headerRow{
+			Name:     name,
+			Response: orDash(redactHeader(name, f.ResponseHeaders[name])),
+			Baseline: orDash(redactHeader(name, f.BaselineHeaders[name])),
+		})
+	}
+	return rows
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
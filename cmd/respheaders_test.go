@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetEvidenceHeaders(t *testing.T) {
+	s := NewScanner(nil, nil)
+	if err := s.SetEvidenceHeaders([]string{"content-type", "", "x-cache"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Content-Type", "X-Cache"}; !reflect.DeepEqual(s.evidenceHeaders, want) {
+		t.Errorf("evidence headers = %q, want %q", s.evidenceHeaders, want)
+	}
+	if err := s.SetEvidenceHeaders(strings.Split("a,b,c,d,e,f,g,h,i,j,k", ",")); err == nil {
+		t.Error("more than maxEvidenceHeaders accepted")
+	}
+}
+
+// cross-user findings carry the attacker's response headers next to the
+// victim baseline's
+func TestFindingsKeepEvidenceHeaders(t *testing.T) {
+	srv := newRecordingServer(t, selftestHandler())
+	s := fastScanner(selftestUsers(), []APIRequest{getRequest(srv.URL + "/api/users/{user_id}")})
+
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) == 0 {
+		t.Fatal("no findings")
+	}
+	for _, f := range findings {
+		if f.ResponseHeaders["Content-Type"] != "application/json" || f.BaselineHeaders["Content-Type"] != "application/json" {
+			t.Errorf("%s→%s headers = %v, baseline %v", f.Attacker, f.Victim, f.ResponseHeaders, f.BaselineHeaders)
+		}
+		if f.ResponseHeaders["Content-Length"] == "" {
+			t.Errorf("%s→%s has no Content-Length", f.Attacker, f.Victim)
+		}
+	}
+}
+
+func TestHeaderRows(t *testing.T) {
+	f := Finding{
+		ResponseHeaders: map[string]string{"Content-Type": "application/json", "Set-Cookie": "session=abc"},
+		BaselineHeaders: map[string]string{"Content-Type": "application/json", "Cache-Control": "no-store"},
+	}
+	want := []headerRow{
+		{Name: "Cache-Control", Response: "-", Baseline: "no-store"},
+		{Name: "Content-Type", Response: "application/json", Baseline: "application/json"},
+		{Name: "Set-Cookie", Response: "***", Baseline: "-"},
+	}
+	if got := headerRows(f); !reflect.DeepEqual(got, want) {
+		t.Errorf("headerRows =\n%+v\nwant\n%+v", got, want)
+	}
+}
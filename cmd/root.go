@@ -1,26 +1,75 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 var (
-	cfgFile        string
-	collectionFile string
-	openapiFile    string
-	harFile        string
-	usersFile      string
-	outputFormat   string
-	outputFile     string
-	proxyURL       string
-	timeoutSecs    int
-	rateLimit      int
-	workers        int
-	verbose        bool
+	cfgFile         string
+	collectionFile  string
+	openapiFile     string
+	harFile         string
+	openapiOptional bool
+	requestsDir     string
+	usersFile       string
+	outputFormat    string
+	outputFile      string
+	checkpointFile  string
+	knownFindings   string
+	summaryJSON     bool
+	proxyURL        string
+	proxyUser       string
+	proxyPass       string
+	forceHTTP2      bool
+	h2c             bool
+	authQueryParams []string
+	evidenceHeaders []string
+	globalHeaders   []string
+	basicAuth       string
+	timeoutSecs     int
+	rateLimit       int
+	workers         int
+	verbose         bool
+	enumStart       int
+	enumEnd         int
+	enumStep        int
+	rebaselineEvery int
+	driftThreshold  float64
+	strictBaseline  bool
+	strict          bool
+	shuffle         bool
+	maxIdleConns    int
+	maxConnsPerHost int
+	largeResponseMB int
+	seed            int64
+	baselineHead    bool
+	sizeTolerance   int
+	similarity      string
+	simThreshold    float64
+	enumerateIDs    bool
+	enumWrites      bool
+	siblingRange    int
+	typeFuzz        bool
+	typeFuzzPost    bool
+	allowCardSwap   bool
+	showDiff        bool
+	maxFindings     int
+	stopOnCritical  bool
+	pauseOn429      int
+	methods         []string
+	endpointFilter  string
+	maxCredentials  int
 )
 
 var rootCmd = &cobra.Command{
@@ -29,7 +78,8 @@ var rootCmd = &cobra.Command{
 	Long: `IDOR-Scan replays API requests with manipulated authentication contexts 
 to identify Insecure Direct Object Reference (IDOR) and Broken Object-Level 
 Authorization (BOLA) vulnerabilities.`,
-	Run: runScan,
+	PersistentPreRunE: applyConfigToFlags,
+	Run:               runScan,
 }
 
 func Execute() {
@@ -43,52 +93,325 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Input sources
-	rootCmd.Flags().StringVarP(&collectionFile, "collection", "c", "", "Postman collection file (JSON)")
-	rootCmd.Flags().StringVarP(&openapiFile, "openapi", "o", "", "OpenAPI spec file (YAML/JSON)")
-	rootCmd.Flags().StringVarP(&harFile, "har", "H", "", "HAR file from browser/proxy")
-	
-	// Required
-	rootCmd.Flags().StringVarP(&usersFile, "users", "u", "", "User contexts file (JSON)")
-	rootCmd.MarkFlagRequired("users")
+	addInputFlags(rootCmd)
+
+	// Filters
+	rootCmd.Flags().StringSliceVar(&methods, "methods", nil, "Only scan these HTTP methods, comma-separated (e.g. GET,HEAD)")
+	rootCmd.Flags().StringVar(&endpointFilter, "endpoint", "", "Only scan requests matching \"METHOD /path\" or a URL substring")
 
 	// Output
-	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "text", "Output format: text, json, html (Pro)")
+	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "text", "Output format: text, json, jsonl, csv, html (Pro)")
 	rootCmd.Flags().StringVarP(&outputFile, "output", "O", "", "Save findings to file")
-	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	rootCmd.Flags().StringVar(&knownFindings, "baseline-findings", "", "Approved findings report; matching findings are listed as known and only new ones fail the run")
+	rootCmd.Flags().BoolVar(&summaryJSON, "summary-json", false, "Write a one-line JSON summary (counts, traffic, exit reason) to stderr at the end")
+	rootCmd.Flags().StringVar(&checkpointFile, "checkpoint", "", "Save progress to this file and resume from it after an interrupt")
+	rootCmd.Flags().IntVar(&maxFindings, "max-findings", 0, "Stop the scan after this many findings (0 = no limit)")
+	rootCmd.Flags().BoolVar(&stopOnCritical, "stop-on-critical", false, "Stop the scan at the first CRITICAL finding")
+	rootCmd.Flags().BoolVar(&showDiff, "diff", false, "Record which JSON fields differ from the attacker's own response (json/html output)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
 
 	// Network
-	rootCmd.Flags().StringVarP(&proxyURL, "proxy", "p", "", "Proxy URL (e.g., http://127.0.0.1:8080 for Burp)")
-	rootCmd.Flags().IntVarP(&timeoutSecs, "timeout", "t", 30, "Request timeout in seconds")
+	addNetworkFlags(rootCmd)
 	rootCmd.Flags().IntVarP(&rateLimit, "rate", "r", 10, "Requests per second")
 	rootCmd.Flags().IntVarP(&workers, "workers", "w", 5, "Number of concurrent workers")
+	rootCmd.Flags().IntVar(&maxIdleConns, "max-idle-conns", 0, "Idle connections kept open per host (0 = one per worker)")
+	rootCmd.Flags().IntVar(&maxConnsPerHost, "max-conns-per-host", 0, "Cap on open connections per host (0 = no cap)")
+	rootCmd.Flags().BoolVar(&shuffle, "shuffle", false, "Randomize the order requests and user pairs are tested in")
+	rootCmd.Flags().Int64Var(&seed, "seed", 0, "Seed for --shuffle, to repeat a run's order (0 = random)")
+	rootCmd.Flags().StringSliceVar(&authQueryParams, "auth-query-params", defaultAuthQueryParams, "Query parameter names stripped from no-auth requests")
+	rootCmd.Flags().StringSliceVar(&evidenceHeaders, "evidence-headers", defaultEvidenceHeaders, "Response headers kept on findings and compared with the baseline (\"\" = none)")
+	rootCmd.Flags().IntVar(&maxCredentials, "max-credentials", defaultMaxCredentials, "Max credential sets tried per attacker (0 = all)")
+	rootCmd.Flags().IntVar(&pauseOn429, "pause-after-429", 5, "Pause all workers after N consecutive 429s, honoring Retry-After (0 = off)")
+
+	// Enumeration
+	rootCmd.Flags().IntVar(&enumStart, "enum-start", 0, "First numeric ID to enumerate (requires --enum-end)")
+	rootCmd.Flags().IntVar(&enumEnd, "enum-end", 0, "Last numeric ID to enumerate (inclusive)")
+	rootCmd.Flags().IntVar(&enumStep, "enum-step", 1, "Increment between enumerated IDs")
+	rootCmd.Flags().BoolVar(&enumWrites, "enum-writes", false, "Let --enum-end and --enumerate-ids sweep POST, PUT, PATCH and DELETE requests (changes other users' objects)")
+	rootCmd.Flags().BoolVar(&enumerateIDs, "enumerate-ids", false, "Probe IDs adjacent to each user's ObjectIds and UUIDv1s (noisy, slow)")
+	rootCmd.Flags().IntVar(&siblingRange, "sibling-range", defaultSiblingRange, "How many adjacent IDs either side --enumerate-ids tries")
+	rootCmd.Flags().BoolVar(&typeFuzz, "typefuzz", false, "Aggressive: resend IDs as arrays, objects, strings and NoSQL operators")
+	rootCmd.Flags().BoolVar(&typeFuzzPost, "typefuzz-post", false, "Let --typefuzz re-type POST requests too (operator bodies may create or change records)")
+	rootCmd.Flags().BoolVar(&allowCardSwap, "allow-card-swap", false, "Let body swaps replace card-like (Luhn-valid) numbers")
+
+	// Baseline drift
+	rootCmd.Flags().IntVar(&rebaselineEvery, "rebaseline-every", 0, "Re-capture a sampled baseline every N endpoints (0 = off)")
+	rootCmd.Flags().Float64Var(&driftThreshold, "drift-threshold", 0.2, "Relative body-size change that counts as baseline drift")
+	rootCmd.Flags().BoolVar(&strictBaseline, "strict-baseline", false, "Abort the scan when baseline drift is detected")
+	rootCmd.Flags().BoolVar(&strict, "strict", false, "Abort when users share credentials or params instead of warning")
+	rootCmd.Flags().BoolVar(&baselineHead, "baseline-head", false, "Size GET baselines with HEAD + Content-Length instead of downloading bodies")
+	rootCmd.Flags().IntVar(&largeResponseMB, "large-response-mb", defaultLargeResponse>>20, "Report responses larger than this many MB (0 = off)")
+	rootCmd.Flags().IntVar(&sizeTolerance, "size-tolerance", defaultSizeTolerance, "Bytes a response may differ from the victim's baseline and still count as same-size")
+	rootCmd.Flags().StringVar(&similarity, "similarity", "size", "How a non-identical body is matched to the victim's baseline: size, jaccard, levenshtein or json")
+	rootCmd.Flags().Float64Var(&simThreshold, "similarity-threshold", defaultSimilarityThreshold, "Score (0-1) at or above which --similarity counts a body as matching")
 	
 	// Config file
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is .idor-scan.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file: .yaml, .yml, .toml or .json (default is .idor-scan.yaml)")
+}
+
+// configTypes maps supported config file extensions to Viper config types
+var configTypes = map[string]string{
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".toml": "toml",
+	".json": "json",
 }
 
 func initConfig() {
 	if cfgFile != "" {
+		ext := strings.ToLower(filepath.Ext(cfgFile))
+		configType, ok := configTypes[ext]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unsupported config file type %q (use .yaml, .yml, .toml or .json)\n", ext)
+			os.Exit(1)
+		}
 		viper.SetConfigFile(cfgFile)
+		viper.SetConfigType(configType)
 	} else {
 		viper.AddConfigPath(".")
 		viper.SetConfigType("yaml")
 		viper.SetConfigName(".idor-scan")
 	}
 
+	// IDOR_SCAN_RATE=20, IDOR_SCAN_ENUM_END=500, ...
+	viper.SetEnvPrefix("idor_scan")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
 
-	if err := viper.ReadInConfig(); err == nil && verbose {
+	if err := viper.ReadInConfig(); err != nil {
+		// A missing default config is fine; an explicit --config must load
+		if cfgFile != "" {
+			fmt.Fprintf(os.Stderr, "Error reading config file: %v\n", err)
+			os.Exit(1)
+		}
+	} else if verbose {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
 
+// addNetworkFlags registers the flags that shape how requests reach the
+// target (proxy, protocol, gateway auth, shared headers) on a command
+func addNetworkFlags(c *cobra.Command) {
+	c.Flags().StringVarP(&proxyURL, "proxy", "p", "", "Proxy URL (e.g., http://127.0.0.1:8080 for Burp)")
+	c.Flags().StringVar(&proxyUser, "proxy-user", "", "Username for an authenticated proxy")
+	c.Flags().StringVar(&proxyPass, "proxy-pass", "", "Password for an authenticated proxy (or IDOR_SCAN_PROXY_PASS)")
+	c.Flags().BoolVar(&forceHTTP2, "http2", false, "Require HTTP/2 over TLS; requests that negotiate HTTP/1.1 fail")
+	c.Flags().BoolVar(&h2c, "h2c", false, "Speak cleartext HTTP/2 (prior knowledge) to http:// targets")
+	c.Flags().IntVarP(&timeoutSecs, "timeout", "t", 30, "Request timeout in seconds")
+	c.Flags().StringArrayVar(&globalHeaders, "header", nil, "Header added to every request, \"Key: Value\" (repeatable)")
+	c.Flags().StringVar(&basicAuth, "basic-auth", "", "Gateway HTTP basic auth sent on every request, user:pass (or IDOR_SCAN_BASIC_AUTH)")
+}
+
+// configureNetwork applies the network flags to a scanner, so a scan and a
+// replay reach the target the same way
+func configureNetwork(scanner *Scanner, users []User) error {
+	if proxyPass != "" && proxyUser == "" {
+		return fmt.Errorf("--proxy-pass requires --proxy-user")
+	}
+	if proxyURL != "" {
+		if verbose {
+			fmt.Printf("🔌 Using proxy: %s\n", redactURL(proxyURL))
+		}
+		if err := scanner.SetProxy(proxyURL); err != nil {
+			return fmt.Errorf("setting proxy: %w", err)
+		}
+	} else if env := envProxy(); env != "" {
+		// The default transport already honors HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+		if verbose {
+			fmt.Printf("🔌 Using proxy from environment: %s\n", redactURL(env))
+		}
+	} else if proxyUser != "" {
+		return fmt.Errorf("--proxy-user requires --proxy or HTTP_PROXY/HTTPS_PROXY")
+	}
+	if proxyUser != "" {
+		if err := scanner.SetProxyAuth(proxyUser, proxyPass); err != nil {
+			return fmt.Errorf("setting proxy credentials: %w", err)
+		}
+	}
+
+	// Speak HTTP/2 to targets that need it; applies through the proxy too
+	if forceHTTP2 || h2c {
+		scanner.SetHTTP2(forceHTTP2, h2c)
+	}
+	scanner.SetTimeout(time.Duration(timeoutSecs) * time.Second)
+
+	headers, err := parseHeaderFlags(globalHeaders)
+	if err != nil {
+		return fmt.Errorf("--header: %w", err)
+	}
+	scanner.SetGlobalHeaders(headers)
+
+	if basicAuth != "" {
+		i := strings.Index(basicAuth, ":")
+		if i < 0 {
+			return fmt.Errorf("--basic-auth must be user:pass")
+		}
+		scanner.SetGatewayBasicAuth(basicAuth[:i], basicAuth[i+1:])
+		if names := authorizationUsers(users); len(names) > 0 {
+			fmt.Fprintf(os.Stderr, "⚠️  Users %s send app auth in Authorization, replacing --basic-auth on their requests; move it to another header if the gateway rejects them\n",
+				strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// addInputFlags registers the request-source and users flags on a command
+func addInputFlags(c *cobra.Command) {
+	c.Flags().StringVarP(&collectionFile, "collection", "c", "", "Postman collection file (JSON)")
+	c.Flags().StringVarP(&openapiFile, "openapi", "o", "", "OpenAPI spec file (YAML/JSON)")
+	c.Flags().BoolVar(&openapiOptional, "openapi-optional", false, "Include optional OpenAPI query and cookie parameters as placeholders")
+	c.Flags().StringVarP(&harFile, "har", "H", "", "HAR file from browser/proxy")
+	c.Flags().StringVar(&requestsDir, "requests-dir", "", "Directory of raw HTTP request files (.http, .req, .txt)")
+
+	// Required
+	c.Flags().StringVarP(&usersFile, "users", "u", "", "User contexts file (JSON)")
+	c.MarkFlagRequired("users")
+}
+
+func hasInputSource() bool {
+	return collectionFile != "" || openapiFile != "" || harFile != "" || requestsDir != ""
+}
+
+// loadRequests parses every input source that was given and merges the
+// requests, keeping the first of any with the same method and URL
+func loadRequests() ([]APIRequest, error) {
+	var requests []APIRequest
+	log := newCLILogger(verbose)
+
+	if collectionFile != "" {
+		if verbose {
+			fmt.Printf("📦 Parsing Postman collection: %s\n", collectionFile)
+		}
+		path, cleanup, err := localInput(collectionFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading collection: %w", err)
+		}
+		defer cleanup()
+		parsed, err := parsePostmanCollection(path, log)
+		if err != nil {
+			return nil, fmt.Errorf("parsing collection: %w", err)
+		}
+		requests = append(requests, parsed...)
+	}
+	if openapiFile != "" {
+		if verbose {
+			fmt.Printf("📦 Parsing OpenAPI spec: %s\n", openapiFile)
+		}
+		path, cleanup, err := localInput(openapiFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading OpenAPI spec: %w", err)
+		}
+		defer cleanup()
+		parsed, err := parseOpenAPISpec(path, openapiOptional, log)
+		if err != nil {
+			return nil, fmt.Errorf("parsing OpenAPI spec: %w", err)
+		}
+		requests = append(requests, parsed...)
+	}
+	if harFile != "" {
+		if verbose {
+			fmt.Printf("📦 Parsing HAR file: %s\n", harFile)
+		}
+		path, cleanup, err := localInput(harFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading HAR file: %w", err)
+		}
+		defer cleanup()
+		parsed, err := parseHARFile(path, log)
+		if err != nil {
+			return nil, fmt.Errorf("parsing HAR file: %w", err)
+		}
+		requests = append(requests, parsed...)
+	}
+	if requestsDir != "" {
+		if verbose {
+			fmt.Printf("📦 Parsing raw requests in: %s\n", requestsDir)
+		}
+		parsed, err := parseRequestsDir(requestsDir, log)
+		if err != nil {
+			return nil, fmt.Errorf("reading requests dir: %w", err)
+		}
+		requests = append(requests, parsed...)
+	}
+
+	return dedupeRequests(requests), nil
+}
+
+// dedupeRequests drops requests whose method, URL and body already appeared,
+// so an endpoint found in several sources is only scanned once. The body is
+// part of the key since one URL can carry many operations (POST /graphql).
+func dedupeRequests(requests []APIRequest) []APIRequest {
+	seen := make(map[string]bool)
+	unique := []APIRequest{}
+	for _, req := range requests {
+		key := strings.ToUpper(req.Method) + " " + req.URL + "\x00" + req.Body
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, req)
+	}
+	if dropped := len(requests) - len(unique); dropped > 0 && verbose {
+		fmt.Printf("🔁 Skipped %d duplicate requests across input sources\n", dropped)
+	}
+	return unique
+}
+
+// redactURL masks any password embedded in a URL for display
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Redacted()
+}
+
+// applyConfigToFlags binds the running command's flags to Viper and copies any
+// env or config-file value into flags not given on the command line, giving
+// flag > env > config > default precedence. Binding happens here rather than in
+// init() because subcommands register their own flags under the same names.
+func applyConfigToFlags(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	if err := viper.BindPFlags(flags); err != nil {
+		return err
+	}
+
+	var applyErr error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if applyErr != nil || f.Changed || f.Name == "config" || !viper.IsSet(f.Name) {
+			return
+		}
+
+		// A config-file list replaces a slice flag outright. Anything else,
+		// such as IDOR_SCAN_METHODS=GET,HEAD, goes through the flag's own
+		// parsing, exactly as if it had been typed on the command line.
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			switch viper.Get(f.Name).(type) {
+			case []interface{}, []string:
+				if err := sv.Replace(viper.GetStringSlice(f.Name)); err != nil {
+					applyErr = fmt.Errorf("config %s: %w", f.Name, err)
+				}
+				f.Changed = true
+				return
+			}
+		}
+
+		if err := flags.Set(f.Name, viper.GetString(f.Name)); err != nil {
+			applyErr = fmt.Errorf("config %s: %w", f.Name, err)
+		}
+	})
+
+	return applyErr
+}
+
 func runScan(cmd *cobra.Command, args []string) {
+	started := time.Now()
 	fmt.Println("🔍 IDOR-Scan v0.1.0")
 	fmt.Println()
 
 	// Validate input
-	if collectionFile == "" && openapiFile == "" && harFile == "" {
-		fmt.Fprintln(os.Stderr, "Error: must specify one of --collection, --openapi, or --har")
+	if !hasInputSource() {
+		fmt.Fprintln(os.Stderr, "Error: must specify at least one of --collection, --openapi, --har, or --requests-dir")
 		os.Exit(1)
 	}
 
@@ -107,34 +430,36 @@ func runScan(cmd *cobra.Command, args []string) {
 		fmt.Printf("✅ Loaded %d user contexts\n\n", len(users))
 	}
 
-	// Load API requests
-	var requests []APIRequest
-	
-	if collectionFile != "" {
-		if verbose {
-			fmt.Printf("📦 Parsing Postman collection: %s\n", collectionFile)
+	// Users that are secretly the same identity make every cross-user result false
+	if shared := sharedIdentityWarnings(users); len(shared) > 0 {
+		for _, w := range shared {
+			fmt.Fprintf(os.Stderr, "⚠️  %s\n", w)
 		}
-		requests, err = parsePostmanCollection(collectionFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing collection: %v\n", err)
-			os.Exit(1)
-		}
-	} else if openapiFile != "" {
-		if verbose {
-			fmt.Printf("📦 Parsing OpenAPI spec: %s\n", openapiFile)
-		}
-		requests, err = parseOpenAPISpec(openapiFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing OpenAPI spec: %v\n", err)
+		if strict {
+			fmt.Fprintln(os.Stderr, "Error: users are not distinct (--strict)")
 			os.Exit(1)
 		}
-	} else if harFile != "" {
-		if verbose {
-			fmt.Printf("📦 Parsing HAR file: %s\n", harFile)
-		}
-		requests, err = parseHARFile(harFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing HAR file: %v\n", err)
+		fmt.Fprintln(os.Stderr)
+	}
+
+	// Load API requests
+	requests, err := loadRequests()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error %v\n", err)
+		os.Exit(1)
+	}
+
+	// Restrict HTTP methods
+	requests, dropped := filterByMethods(requests, methods)
+	if verbose && dropped > 0 {
+		fmt.Printf("🔎 Filtered out %d requests not matching --methods %s\n", dropped, strings.Join(methods, ","))
+	}
+
+	// Narrow to a single endpoint
+	if endpointFilter != "" {
+		requests = filterByEndpoint(requests, endpointFilter)
+		if len(requests) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: --endpoint %q matches no requests\n", endpointFilter)
 			os.Exit(1)
 		}
 	}
@@ -147,53 +472,205 @@ func runScan(cmd *cobra.Command, args []string) {
 
 	// Run scan with baseline comparison for accuracy
 	scanner := NewScanner(users, requests)
+	scanner.SetLogger(newCLILogger(verbose))
 	
-	// Configure proxy if specified
-	if proxyURL != "" {
+	// Proxy, protocol, timeout, gateway auth and shared headers
+	if err := configureNetwork(scanner, users); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Configure rate limit
+	scanner.SetRateLimit(rateLimit)
+	scanner.SetPauseOn429(pauseOn429)
+	scanner.SetMaxCredentials(maxCredentials)
+	scanner.SetConnPool(maxIdleConns, maxConnsPerHost)
+	scanner.SetLargeResponse(largeResponseMB << 20)
+	scanner.SetAuthQueryParams(authQueryParams)
+	if err := scanner.SetEvidenceHeaders(evidenceHeaders); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Configure explicit ID locations from the config file
+	var idLocations []IDLocation
+	if err := viper.UnmarshalKey("id_locations", &idLocations); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading id_locations: %v\n", err)
+		os.Exit(1)
+	}
+	if err := scanner.SetIDLocations(idLocations); err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring id_locations: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Display labels for severities (e.g. P0/P1/P2) from the config file
+	if err := SetSeverityLabels(viper.GetStringMapString("severity_labels")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring severity labels: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Weight findings by endpoint sensitivity from the config file
+	var sensitivity []SensitivityRule
+	if err := viper.UnmarshalKey("sensitivity", &sensitivity); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading sensitivity: %v\n", err)
+		os.Exit(1)
+	}
+	if err := scanner.SetSensitivity(sensitivity); err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring sensitivity: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Configure numeric ID enumeration
+	if cmd.Flags().Changed("enum-end") {
+		if err := scanner.SetEnumRange(enumStart, enumEnd, enumStep); err != nil {
+			fmt.Fprintf(os.Stderr, "Error configuring enumeration: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Probe structural neighbours of ObjectIds / UUIDv1s
+	if enumerateIDs {
+		scanner.SetSiblingEnum(siblingRange)
+	}
+	if enumWrites {
+		if !cmd.Flags().Changed("enum-end") && !enumerateIDs {
+			fmt.Fprintln(os.Stderr, "Error: --enum-writes requires --enum-end or --enumerate-ids")
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "⚠️  --enum-writes replays writes against other users' IDs; they may be changed or deleted\n")
+		scanner.SetEnumWrites(true)
+	}
+
+	// Re-type IDs to probe type confusion and operator injection
+	if typeFuzz {
+		fmt.Fprintf(os.Stderr, "⚠️  --typefuzz sends malformed IDs and NoSQL operators; only run it where injection testing is authorized\n")
+		scanner.SetTypeFuzz(true)
+		scanner.SetTypeFuzzPost(typeFuzzPost)
+	} else if typeFuzzPost {
+		fmt.Fprintln(os.Stderr, "Error: --typefuzz-post requires --typefuzz")
+		os.Exit(1)
+	}
+
+	// Card-like numbers in bodies are left alone unless asked
+	if allowCardSwap {
+		fmt.Fprintf(os.Stderr, "⚠️  --allow-card-swap may rewrite card numbers in request bodies; use test cards only\n")
+		scanner.SetCardSwap(true)
+	}
+
+	// Configure baseline drift checks
+	scanner.SetDriftCheck(rebaselineEvery, driftThreshold, strictBaseline)
+	scanner.SetBaselineHead(baselineHead)
+	scanner.SetSizeTolerance(sizeTolerance)
+	if err := scanner.SetSimilarity(similarity, simThreshold); err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring similarity: %v\n", err)
+		os.Exit(1)
+	}
+	scanner.SetDiff(showDiff)
+	scanner.SetFindingLimit(maxFindings, stopOnCritical)
+
+	if shuffle {
+		used := scanner.SetShuffle(true, seed)
 		if verbose {
-			fmt.Printf("🔌 Using proxy: %s\n", proxyURL)
+			fmt.Printf("🔀 Shuffling test order (--seed %d to repeat)\n", used)
 		}
-		if err := scanner.SetProxy(proxyURL); err != nil {
-			fmt.Fprintf(os.Stderr, "Error setting proxy: %v\n", err)
+	}
+
+	// Resume an interrupted scan
+	if checkpointFile != "" {
+		done, err := scanner.SetCheckpoint(checkpointFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading checkpoint: %v\n", err)
 			os.Exit(1)
 		}
+		if done > 0 {
+			fmt.Printf("⏩ Resuming from %s: %d tests already done\n", checkpointFile, done)
+		}
 	}
-	
-	// Configure rate limit
-	scanner.SetRateLimit(rateLimit)
-	
+
+	// Findings already accepted in an earlier report
+	var known map[string]bool
+	if knownFindings != "" {
+		known, err = loadKnownFindings(knownFindings)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading baseline findings: %v\n", err)
+			os.Exit(1)
+		}
+		scanner.SetKnownFindings(known)
+		fmt.Printf("📋 Loaded %d known findings from %s\n", len(known), knownFindings)
+	}
+
+	// Stop cleanly on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Stream findings to the output file as they're produced
+	fileFormat := outputFormat
+	if fileFormat == "text" {
+		fileFormat = "json" // Default to JSON for file output
+	}
+	var stream *findingStream
+	if outputFile != "" && streamableFormats[fileFormat] {
+		stream, err = openFindingStream(outputFile, fileFormat, newCLILogger(verbose))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening output file: %v\n", err)
+			os.Exit(1)
+		}
+		scanner.OnFinding(func(f Finding) {
+			if !known[f.ID] {
+				stream.Write(f)
+			}
+		})
+	}
+
 	// Run scan (concurrent if workers > 1)
-	var findings []Finding
-	if workers > 1 {
-		findings = scanner.RunWithBaselineConcurrent(workers)
-	} else {
-		findings = scanner.RunWithBaseline()
+	scanner.SetWorkers(workers)
+	findings, scanErr := scanner.Scan(ctx)
+	if scanErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: scan aborted: %v\n", scanErr)
+	}
+	// Only new findings are reported; accepted ones are listed after the summary
+	findings, seen := splitKnown(findings, known)
+
+	// exit ends the run, writing the --summary-json line first
+	exit := func(code int, reason string, err error) {
+		if summaryJSON {
+			summary := newScanSummary(findings, scanner.Traffic(), time.Since(started))
+			summary.Known = len(seen)
+			writeSummaryJSON(os.Stderr, summary, code, reason, err)
+		}
+		if code != 0 {
+			os.Exit(code)
+		}
 	}
 
 	// Output results
 	var output string
-	if outputFormat == "json" {
+	switch outputFormat {
+	case "json":
 		output = formatJSON(findings)
-		if outputFile == "" {
-			fmt.Println(output)
-		}
-	} else if outputFormat == "html" {
+	case "jsonl":
+		output = formatJSONL(findings)
+	case "csv":
+		output = formatCSV(findings)
+	case "html":
 		output = formatHTML(findings)
-		if outputFile == "" {
-			fmt.Println(output)
-		}
-	} else {
+	default:
 		outputText(findings)
 	}
+	if output != "" && outputFile == "" {
+		fmt.Println(output)
+	}
 
 	// Save to file if specified
 	if outputFile != "" {
-		if outputFormat == "text" {
-			output = formatJSON(findings) // Default to JSON for file output
+		if stream != nil {
+			err = stream.Close(findings)
+		} else {
+			err = os.WriteFile(outputFile, []byte(output), 0644)
 		}
-		if err := os.WriteFile(outputFile, []byte(output), 0644); err != nil {
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
-			os.Exit(1)
+			exit(1, exitOutputError, err)
 		}
 		fmt.Printf("💾 Findings saved to: %s\n", outputFile)
 	}
@@ -205,25 +682,60 @@ func runScan(cmd *cobra.Command, args []string) {
 	critical := 0
 	high := 0
 	medium := 0
+	info := 0
 	
 	for _, f := range findings {
 		switch f.Severity {
-		case "CRITICAL":
+		case SeverityCritical:
 			critical++
-		case "HIGH":
+		case SeverityHigh:
 			high++
-		case "MEDIUM":
+		case SeverityMedium:
 			medium++
+		case SeverityInfo:
+			info++
 		}
 	}
 	
 	if critical > 0 {
-		fmt.Printf("   🔴 Critical: %d\n", critical)
+		fmt.Printf("   🔴 %s: %d\n", SeverityCritical.Title(), critical)
 	}
 	if high > 0 {
-		fmt.Printf("   🟠 High: %d\n", high)
+		fmt.Printf("   🟠 %s: %d\n", SeverityHigh.Title(), high)
 	}
 	if medium > 0 {
-		fmt.Printf("   🟡 Medium: %d\n", medium)
+		fmt.Printf("   🟡 %s: %d\n", SeverityMedium.Title(), medium)
+	}
+	if info > 0 {
+		fmt.Printf("   ℹ️  %s: %d (failed requests, large responses)\n", SeverityInfo.Title(), info)
+	}
+
+	if len(seen) > 0 {
+		fmt.Printf("📋 Known findings (in %s): %d\n", knownFindings, len(seen))
+		for _, f := range seen {
+			fmt.Printf("   [%s] %s %s (%s)\n", f.Severity.Label(), f.Method, f.Endpoint, f.ID)
+		}
+	}
+
+	traffic := scanner.Traffic()
+	fmt.Printf("📦 Traffic: %d requests, %s sent, %s received\n",
+		traffic.Requests, formatBytes(traffic.BytesSent), formatBytes(traffic.BytesReceived))
+	if checkpointFile != "" {
+		if _, err := os.Stat(checkpointFile); err == nil {
+			fmt.Printf("⏸️  Scan incomplete; progress saved to %s (rerun with the same --checkpoint to resume)\n", checkpointFile)
+		}
+	}
+
+	switch {
+	case scanErr != nil:
+		exit(1, exitScanError, scanErr)
+	case knownFindings != "" && gatedFindings(findings) > 0:
+		exit(1, exitNewFindings, nil)
+	case ctx.Err() != nil:
+		exit(130, exitInterrupted, nil) // 128+SIGINT, as shells report Ctrl+C
+	case scanner.limitHit:
+		exit(0, exitFindingLimit, nil)
+	default:
+		exit(0, exitComplete, nil)
 	}
 }
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// resetFlags puts every root flag back to its default once the test ends
+func resetFlags(t *testing.T) {
+	t.Cleanup(func() {
+		viper.Reset()
+		cfgFile = ""
+		rootCmd.Flags().VisitAll(func(f *pflag.Flag) {
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				def := strings.Trim(f.DefValue, "[]")
+				list := []string{}
+				if def != "" {
+					list = strings.Split(def, ",")
+				}
+				sv.Replace(list)
+			} else {
+				f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		})
+	})
+}
+
+// loadConfig reads path the way the root command does before a scan
+func loadConfig(t *testing.T, path string) {
+	t.Helper()
+	resetFlags(t)
+	viper.Reset()
+	cfgFile = path
+	initConfig()
+	if err := applyConfigToFlags(rootCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigFormats(t *testing.T) {
+	configs := map[string]string{
+		".idor-scan.yaml": `
+rate: 20
+workers: 2
+methods: [GET, DELETE]
+header:
+  - "X-Tenant: acme"
+severity_labels:
+  critical: P0
+id_locations:
+  - pattern: /api/orders
+    param: order_id
+    path_index: 2
+`,
+		".idor-scan.toml": `
+rate = 20
+workers = 2
+methods = ["GET", "DELETE"]
+header = ["X-Tenant: acme"]
+
+[severity_labels]
+critical = "P0"
+
+[[id_locations]]
+pattern = "/api/orders"
+param = "order_id"
+path_index = 2
+`,
+	}
+
+	for name, content := range configs {
+		t.Run(name, func(t *testing.T) {
+			loadConfig(t, writeTemp(t, name, content))
+
+			if rateLimit != 20 || workers != 2 {
+				t.Errorf("rate, workers = %d, %d, want 20, 2", rateLimit, workers)
+			}
+			if !reflect.DeepEqual(methods, []string{"GET", "DELETE"}) {
+				t.Errorf("methods = %q", methods)
+			}
+			if !reflect.DeepEqual(globalHeaders, []string{"X-Tenant: acme"}) {
+				t.Errorf("header = %q", globalHeaders)
+			}
+			if labels := viper.GetStringMapString("severity_labels"); labels["critical"] != "P0" {
+				t.Errorf("severity_labels = %v", labels)
+			}
+			var locs []IDLocation
+			if err := viper.UnmarshalKey("id_locations", &locs); err != nil {
+				t.Fatal(err)
+			}
+			if len(locs) != 1 || locs[0].Param != "order_id" || locs[0].PathIndex == nil || *locs[0].PathIndex != 2 {
+				t.Errorf("id_locations = %+v", locs)
+			}
+		})
+	}
+}
+
+// Environment values for slice flags go through the flag's own parsing
+func TestEnvSliceFlags(t *testing.T) {
+	t.Setenv("IDOR_SCAN_METHODS", "GET,HEAD")
+	t.Setenv("IDOR_SCAN_HEADER", "X-Tenant: acme")
+	loadConfig(t, writeTemp(t, "empty.yaml", "{}\n"))
+
+	requests := []APIRequest{{Method: "GET"}, {Method: "HEAD"}, {Method: "DELETE"}}
+	if kept, _ := filterByMethods(requests, methods); len(kept) != 2 {
+		t.Errorf("methods %q kept %d of GET, HEAD, DELETE; want 2", methods, len(kept))
+	}
+
+	headers, err := parseHeaderFlags(globalHeaders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := headers.Get("X-Tenant"); got != "acme" {
+		t.Errorf("X-Tenant = %q, want acme", got)
+	}
+}
+
+// flag > env > config > default
+func TestConfigPrecedence(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		env, flag string
+		want      int
+	}{
+		{"default", "{}\n", "", "", 10},
+		{"config", "rate: 20\n", "", "", 20},
+		{"env over config", "rate: 20\n", "30", "", 30},
+		{"flag over env", "rate: 20\n", "30", "40", 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("IDOR_SCAN_RATE", tt.env)
+			}
+			resetFlags(t)
+			if tt.flag != "" {
+				if err := rootCmd.Flags().Set("rate", tt.flag); err != nil {
+					t.Fatal(err)
+				}
+			}
+			loadConfig(t, writeTemp(t, "config.yaml", tt.config))
+
+			s := NewScanner(nil, nil)
+			s.SetRateLimit(rateLimit)
+			if want := time.Second / time.Duration(tt.want); s.rateDelay != want {
+				t.Errorf("rate delay = %s, want %s (%d/s)", s.rateDelay, want, tt.want)
+			}
+		})
+	}
+}
+
+// GraphQL operations share one URL, so only an identical body is a duplicate
+func TestDedupeRequestsKeepsDistinctBodies(t *testing.T) {
+	post := func(body string) APIRequest {
+		return APIRequest{Method: "POST", URL: "https://api.example.com/graphql", Body: body}
+	}
+	requests := []APIRequest{
+		post(`{"query":"{ me { id } }"}`),
+		post(`{"query":"{ orders { id } }"}`),
+		post(`{"query":"{ me { id } }"}`),
+		{Method: "get", URL: "https://api.example.com/users/1"},
+		{Method: "GET", URL: "https://api.example.com/users/1"},
+	}
+	got := dedupeRequests(requests)
+	if len(got) != 3 {
+		t.Fatalf("kept %d requests, want 3: %+v", len(got), got)
+	}
+	if got[0].Body == got[1].Body {
+		t.Errorf("distinct GraphQL operations collapsed: %+v", got)
+	}
+}
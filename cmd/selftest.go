@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/itxdeeni/idor-scan/internal/testserver"
+	"github.com/spf13/cobra"
+)
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Scan a built-in vulnerable server to check the install works",
+	Long: `Selftest starts the demo server from examples/test-server.go in-process on a
+random local port, scans it with a built-in collection and two users, and
+checks that the IDORs on /api/users/{id} and /api/users/{id}/orders are found
+and the public /health endpoint is not flagged. Nothing leaves the machine.`,
+	Run: runSelftest,
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+}
+
+// selftestCheck is one expectation about the selftest scan's findings
+type selftestCheck struct {
+	name string
+	ok   func(findings []Finding) bool
+}
+
+// crossUserOn reports whether a CRITICAL cross-user finding hit endpoint
+func crossUserOn(findings []Finding, endpoint string) bool {
+	for _, f := range findings {
+		if f.Endpoint == endpoint && f.Severity == SeverityCritical && f.Attacker != "" && f.Victim != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func runSelftest(cmd *cobra.Command, args []string) {
+	server := httptest.NewServer(testserver.Handler())
+	defer server.Close()
+
+	profile := server.URL + "/api/users/123"
+	orders := server.URL + "/api/users/{user_id}/orders"
+	health := server.URL + "/health"
+
+	requests := []APIRequest{
+		{Method: "GET", URL: profile, Headers: make(http.Header), Params: make(map[string]string)},
+		{Method: "GET", URL: orders, Headers: make(http.Header), Params: make(map[string]string)},
+		{Method: "GET", URL: health, Headers: make(http.Header), Params: make(map[string]string)},
+	}
+	users := []User{
+		{Name: "alice", Headers: map[string]string{"Authorization": "Bearer alice-token"}, Params: map[string]string{"user_id": "123"}},
+		{Name: "bob", Headers: map[string]string{"Authorization": "Bearer bob-token"}, Params: map[string]string{"user_id": "456"}},
+	}
+
+	fmt.Printf("🧪 Scanning the built-in vulnerable server at %s\n", server.URL)
+
+	scanner := NewScanner(users, requests)
+	scanner.SetLogger(newCLILogger(verbose))
+	scanner.SetRateLimit(100)
+	findings, err := scanner.Scan(context.Background())
+	if err != nil {
+		fmt.Printf("❌ Scan failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	checks := []selftestCheck{
+		{"IDOR on /api/users/{id} detected", func(fs []Finding) bool { return crossUserOn(fs, profile) }},
+		{"IDOR on /api/users/{id}/orders detected", func(fs []Finding) bool { return crossUserOn(fs, orders) }},
+		{"/health not flagged", func(fs []Finding) bool {
+			for _, f := range fs {
+				if f.Endpoint == health {
+					return false
+				}
+			}
+			return true
+		}},
+	}
+
+	failed := 0
+	for _, c := range checks {
+		if c.ok(findings) {
+			fmt.Printf("✅ %s\n", c.name)
+		} else {
+			fmt.Printf("❌ %s\n", c.name)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n📊 Selftest failed: %d of %d checks (%d findings; rerun with -v for details)\n", failed, len(checks), len(findings))
+		os.Exit(1)
+	}
+	fmt.Printf("\n📊 Selftest passed: %d checks, %d findings\n", len(checks), len(findings))
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SensitivityRule tags findings on matching endpoints and raises them to a
+// minimum severity. Configured under `sensitivity` in the config file.
+type SensitivityRule struct {
+	Pattern     string   `mapstructure:"pattern"`      // substring of the request URL
+	Method      string   `mapstructure:"method"`       // optional HTTP method filter
+	Tag         string   `mapstructure:"tag"`          // shown on findings, e.g. "payments"
+	MinSeverity Severity `mapstructure:"min_severity"` // CRITICAL, HIGH or MEDIUM, or their labels
+}
+
+// Matches reports whether the rule applies to the finding's endpoint
+func (r SensitivityRule) Matches(f Finding) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, f.Method) {
+		return false
+	}
+	return strings.Contains(f.Endpoint, r.Pattern)
+}
+
+// SetSensitivity validates and installs the sensitivity rules. The first
+// rule matching a finding applies. Call it after SetSeverityLabels so a
+// min_severity may name a label.
+func (s *Scanner) SetSensitivity(rules []SensitivityRule) error {
+	for i, r := range rules {
+		if r.Pattern == "" {
+			return fmt.Errorf("sensitivity entries need a pattern")
+		}
+		if r.Tag == "" && r.MinSeverity == "" {
+			return fmt.Errorf("sensitivity entry %q needs a tag or min_severity", r.Pattern)
+		}
+		if r.MinSeverity != "" {
+			sev, ok := ParseSeverity(string(r.MinSeverity))
+			if !ok || sev == SeverityInfo {
+				return fmt.Errorf("sensitivity entry %q: min_severity must be CRITICAL, HIGH or MEDIUM", r.Pattern)
+			}
+			rules[i].MinSeverity = sev
+		}
+	}
+	s.sensitivity = rules
+	return nil
+}
+
+// applySensitivity tags f with the first matching rule and raises its
+// severity to the rule's floor. Informational findings are left as they are.
+func (s *Scanner) applySensitivity(f *Finding) {
+	if f.Severity == SeverityInfo {
+		return
+	}
+	for _, r := range s.sensitivity {
+		if !r.Matches(*f) {
+			continue
+		}
+		f.Sensitivity = r.Tag
+		if r.MinSeverity != "" && f.Severity.Rank() > r.MinSeverity.Rank() {
+			f.Severity = r.MinSeverity
+		}
+		return
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import "testing"
+
+func TestApplySensitivity(t *testing.T) {
+	s := NewScanner(nil, nil)
+	err := s.SetSensitivity([]SensitivityRule{
+		{Pattern: "/payments/", Method: "get", Tag: "payments", MinSeverity: "critical"},
+		{Pattern: "/payments/", Tag: "payments"},
+		{Pattern: "/admin", MinSeverity: "HIGH"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		method, endpoint string
+		severity         Severity
+		wantSeverity     Severity
+		wantTag          string
+	}{
+		{"GET", "/api/payments/9", SeverityMedium, SeverityCritical, "payments"},
+		{"POST", "/api/payments/9", SeverityMedium, SeverityMedium, "payments"},
+		{"GET", "/admin/users", SeverityMedium, SeverityHigh, ""},
+		{"GET", "/admin/users", SeverityCritical, SeverityCritical, ""}, // a floor never lowers
+		{"GET", "/api/payments/9", SeverityInfo, SeverityInfo, ""},
+		{"GET", "/api/profile", SeverityMedium, SeverityMedium, ""},
+	} {
+		f := Finding{Method: tc.method, Endpoint: tc.endpoint, Severity: tc.severity}
+		s.applySensitivity(&f)
+		if f.Severity != tc.wantSeverity || f.Sensitivity != tc.wantTag {
+			t.Errorf("%s %s at %s: got %s %q, want %s %q", tc.method, tc.endpoint, tc.severity, f.Severity, f.Sensitivity, tc.wantSeverity, tc.wantTag)
+		}
+	}
+}
+
+func TestSetSensitivityRejectsBadRules(t *testing.T) {
+	for _, rule := range []SensitivityRule{
+		{Tag: "no pattern"},
+		{Pattern: "/x"},
+		{Pattern: "/x", MinSeverity: "INFO"},
+		{Pattern: "/x", MinSeverity: "urgent"},
+	} {
+		if err := NewScanner(nil, nil).SetSensitivity([]SensitivityRule{rule}); err == nil {
+			t.Errorf("SetSensitivity accepted %+v", rule)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severity is a finding's canonical level. Counting, sorting and the finding
+// limits always work on these; SetSeverityLabels only changes how they are
+// shown.
+type Severity string
+
+const (
+	SeverityCritical Severity = "CRITICAL"
+	SeverityHigh     Severity = "HIGH"
+	SeverityMedium   Severity = "MEDIUM"
+	SeverityInfo     Severity = "INFO"
+)
+
+// severities lists the canonical levels from most to least severe
+var severities = []Severity{SeverityCritical, SeverityHigh, SeverityMedium, SeverityInfo}
+
+// severityLabels maps canonical levels to display labels (e.g. CRITICAL → P0)
+var severityLabels = map[Severity]string{}
+
+// SetSeverityLabels installs display labels for the canonical levels, keyed
+// by level name in any case. Unmapped levels keep their own name.
+func SetSeverityLabels(labels map[string]string) error {
+	mapped := map[Severity]string{}
+	used := map[string]Severity{}
+	for name, label := range labels {
+		sev := Severity(strings.ToUpper(name))
+		if sev.Rank() == len(severities) {
+			return fmt.Errorf("unknown severity %q in severity_labels (want CRITICAL, HIGH, MEDIUM or INFO)", name)
+		}
+		label = strings.TrimSpace(label)
+		if label == "" {
+			return fmt.Errorf("severity_labels: empty label for %s", sev)
+		}
+		if other, ok := used[strings.ToUpper(label)]; ok {
+			return fmt.Errorf("severity_labels: %q is used for both %s and %s", label, other, sev)
+		}
+		used[strings.ToUpper(label)] = sev
+		mapped[sev] = label
+	}
+	severityLabels = mapped
+	return nil
+}
+
+// ParseSeverity reads a canonical level or a configured label, in any case
+func ParseSeverity(name string) (Severity, bool) {
+	for _, sev := range severities {
+		if strings.EqualFold(name, string(sev)) || strings.EqualFold(name, severityLabels[sev]) && name != "" {
+			return sev, true
+		}
+	}
+	return "", false
+}
+
+// Rank orders severities for sorting (lower is more severe)
+func (sev Severity) Rank() int {
+	for i, s := range severities {
+		if s == sev {
+			return i
+		}
+	}
+	return len(severities)
+}
+
+// Label is the level as shown in reports: its configured label, or its name
+func (sev Severity) Label() string {
+	if label, ok := severityLabels[sev]; ok {
+		return label
+	}
+	return string(sev)
+}
+
+// Title is the label for summaries and headings: a configured label as is,
+// otherwise the name in title case ("Critical")
+func (sev Severity) Title() string {
+	if label, ok := severityLabels[sev]; ok {
+		return label
+	}
+	return string(sev[:1]) + strings.ToLower(string(sev[1:]))
+}
+
+// defaultSizeTolerance is how many bytes a response may differ from the victim's
+// baseline and still count as "the same size"
+const defaultSizeTolerance = 50
+
+// SetSizeTolerance sets the byte difference within which a cross-user response
+// is treated as matching the victim's baseline size
+func (s *Scanner) SetSizeTolerance(bytes int) {
+	if bytes < 0 {
+		bytes = 0
+	}
+	s.sizeTolerance = bytes
+}
+
+// classifyCrossUser decides the severity of a 2xx cross-user response against the
+// victim's baseline. Severity matrix:
+//
+//	CRITICAL  body hash equals the victim's baseline hash
+//	HIGH      body matches the baseline under the similarity metric: size
+//	          within tolerance by default (content differs or no hash)
+//	MEDIUM    no match, body over the size tolerance in length
+//
+// crossUserFinding refines this for list responses and partial exposure.
+//
+// It returns "" when the response is not a finding, plus the similarity
+// score for evidence when a metric other than size decided.
+func (s *Scanner) classifyCrossUser(status int, body []byte, baseline Baseline) (Severity, string) {
+	if status != 200 && status != 201 || baseline.BodySize == 0 {
+		return "", ""
+	}
+
+	if !baseline.HeadOnly && hashBody(body) == baseline.BodyHash {
+		return SeverityCritical, ""
+	}
+
+	match, score := s.matchesBaseline(body, baseline)
+	if match {
+		return SeverityHigh, score
+	}
+
+	if len(body) > s.sizeTolerance {
+		return SeverityMedium, score
+	}
+
+	return "", ""
+}
+
+// crossUserFinding builds the finding for an attacker's response to a request
+// against the victim's resource, or nil if it doesn't warrant one. The
+// sequential and concurrent scan paths both go through here. mismatch reports
+// that the response's Content-Length disagreed with the bytes read; own is the
+// attacker's baseline for the endpoint (zero if none).
+func (s *Scanner) crossUserFinding(req APIRequest, attacker, victim User, status int, body []byte, mismatch bool, baseline, own Baseline) *Finding {
+	severity, score := s.classifyCrossUser(status, body, baseline)
+	evidence := fmt.Sprintf("Status: %d, Size: %d bytes (victim baseline: %d bytes)", status, len(body), baseline.BodySize)
+	if score != "" {
+		evidence += ", " + score
+	}
+
+	// List lengths vary per user, so for list responses whether the victim's
+	// items show up decides the finding, not the size
+	var description string
+	kind := kindCrossUser
+	if severity != "" && len(baseline.ItemIDs) > 0 {
+		if got, isList := extractItemIDs(body); isList {
+			leaked := containedItems(baseline.ItemIDs, own.ItemIDs, got)
+			if len(leaked) == 0 {
+				return nil
+			}
+			severity = SeverityCritical
+			kind = kindCrossUserList
+			description = fmt.Sprintf("%s received %d of '%s's items in a list response", attackerLabel(attacker), len(leaked), victim.Name)
+			evidence = fmt.Sprintf("Status: %d, victim item IDs present: %s", status, summarizeIDs(leaked))
+		}
+	}
+
+	// A response that isn't the victim's but still carries some of their
+	// sensitive values leaks those fields
+	if description == "" && severity != SeverityCritical && status >= 200 && status < 300 {
+		if leaked := leakedFields(baseline.Sensitive, own.Sensitive, body); len(leaked) > 0 {
+			if severity == SeverityHigh {
+				evidence += fmt.Sprintf("; victim values present for: %s", summarizeIDs(leaked))
+			} else {
+				severity = SeverityMedium
+				kind = kindPartialExposure
+				description, evidence = describePartialExposure(attacker, victim, status, leaked)
+			}
+		}
+	}
+
+	switch {
+	case description != "":
+	case severity == SeverityCritical:
+		description = fmt.Sprintf("%s accessed '%s's data (response matches victim's baseline)", attackerLabel(attacker), victim.Name)
+	case severity == SeverityHigh:
+		description = fmt.Sprintf("%s got a response the size of '%s's baseline (content not confirmed identical)", attackerLabel(attacker), victim.Name)
+	case severity == SeverityMedium:
+		description = fmt.Sprintf("%s got %d accessing '%s's resource (size differs from baseline)", attackerLabel(attacker), status, victim.Name)
+	default:
+		return nil
+	}
+
+	return &Finding{
+		Kind:        kind,
+		Severity:    severity,
+		Endpoint:    req.URL,
+		Method:      req.Method,
+		Description: description,
+		Evidence:    evidence,
+		Timestamp:   time.Now(),
+		Attacker:    attacker.Name,
+		Victim:      victim.Name,
+		Anonymous:   attacker.IsAnonymous(),
+		Credential:  attacker.credential,
+
+		SizeMismatch: mismatch || baseline.SizeMismatch,
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// The severity matrix: only a hash match is CRITICAL, a size within
+// --size-tolerance is HIGH, and a larger mismatch is MEDIUM
+func TestClassifyCrossUser(t *testing.T) {
+	victim := []byte(`{"id":"456","name":"Bob","email":"bob@example.com"}`)
+	baseline := Baseline{StatusCode: 200, BodySize: len(victim), BodyHash: hashBody(victim)}
+	sameSize := []byte(strings.Replace(string(victim), "bob@", "eve@", 1))
+	longer := []byte(string(victim) + strings.Repeat(" ", 80))
+
+	for _, tc := range []struct {
+		name      string
+		tolerance int
+		status    int
+		body      []byte
+		baseline  Baseline
+		want      Severity
+	}{
+		{"identical", 50, 200, victim, baseline, SeverityCritical},
+		{"same size, other content", 50, 200, sameSize, baseline, SeverityHigh},
+		{"HEAD baseline has no hash", 50, 200, victim, Baseline{StatusCode: 200, BodySize: len(victim), HeadOnly: true}, SeverityHigh},
+		{"within tolerance", 100, 200, longer, baseline, SeverityHigh},
+		{"over tolerance", 50, 200, longer, baseline, SeverityMedium},
+		{"short body over tolerance", 10, 201, []byte(`{}`), baseline, ""},
+		{"denied", 50, 403, victim, baseline, ""},
+		{"empty baseline", 50, 200, victim, Baseline{StatusCode: 200}, ""},
+	} {
+		s := NewScanner(nil, nil)
+		s.SetSizeTolerance(tc.tolerance)
+		if got, _ := s.classifyCrossUser(tc.status, tc.body, tc.baseline); got != tc.want {
+			t.Errorf("%s: severity = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
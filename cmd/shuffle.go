@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// userPair is one attacker/victim combination tested against a request
+type userPair struct {
+	attacker User
+	victim   User
+}
+
+// SetShuffle randomizes the order requests and user pairs are tested in, so
+// traffic is less regular and rate limits don't always land on the same
+// endpoints. A zero seed picks one from the clock; the seed used is returned
+// so a run can be repeated.
+func (s *Scanner) SetShuffle(enabled bool, seed int64) int64 {
+	if !enabled {
+		s.shuffle = nil
+		return 0
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s.shuffle = rand.New(rand.NewSource(seed))
+	return seed
+}
+
+// testRequests returns the requests in test order
+func (s *Scanner) testRequests() []APIRequest {
+	reqs := append([]APIRequest(nil), s.Requests...)
+	if s.shuffle != nil {
+		s.shuffle.Shuffle(len(reqs), func(i, j int) { reqs[i], reqs[j] = reqs[j], reqs[i] })
+	}
+	return reqs
+}
+
+// testPairs returns every attacker/victim pair canTest allows, in test order
+func (s *Scanner) testPairs() []userPair {
+	pairs := []userPair{}
+	for _, attacker := range s.attackers() {
+		for _, victim := range s.Users {
+			if canTest(attacker, victim) {
+				pairs = append(pairs, userPair{attacker, victim})
+			}
+		}
+	}
+	if s.shuffle != nil {
+		s.shuffle.Shuffle(len(pairs), func(i, j int) { pairs[i], pairs[j] = pairs[j], pairs[i] })
+	}
+	return pairs
+}
+
+// sortFindings orders findings by severity, then endpoint and user pair, so
+// reports are the same whatever order the tests ran in
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if ra, rb := a.Severity.Rank(), b.Severity.Rank(); ra != rb {
+			return ra < rb
+		}
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		if a.Attacker != b.Attacker {
+			return a.Attacker < b.Attacker
+		}
+		if a.Victim != b.Victim {
+			return a.Victim < b.Victim
+		}
+		if a.Credential != b.Credential {
+			return a.Credential < b.Credential
+		}
+		return a.Description < b.Description
+	})
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func shuffleRequests() []APIRequest {
+	requests := []APIRequest{}
+	for i := 0; i < 20; i++ {
+		requests = append(requests, getRequest(fmt.Sprintf("https://api.example.com/r/%d", i)))
+	}
+	return requests
+}
+
+// The same seed repeats the same order; no shuffle keeps the input order
+func TestShuffleSeedRepeatsOrder(t *testing.T) {
+	order := func(seed int64, enabled bool) []string {
+		s := NewScanner(selftestUsers(), shuffleRequests())
+		s.SetShuffle(enabled, seed)
+		return requestLines(s.testRequests())
+	}
+
+	if got, want := order(0, false), requestLines(shuffleRequests()); !reflect.DeepEqual(got, want) {
+		t.Errorf("unshuffled order = %q", got)
+	}
+	if a, b := order(42, true), order(42, true); !reflect.DeepEqual(a, b) {
+		t.Errorf("seed 42 gave %q then %q", a, b)
+	}
+	if a, b := order(42, true), order(43, true); reflect.DeepEqual(a, b) {
+		t.Errorf("seeds 42 and 43 gave the same order %q", a)
+	}
+
+	s := NewScanner(nil, nil)
+	if seed := s.SetShuffle(true, 0); seed == 0 {
+		t.Error("a zero seed was not replaced with one from the clock")
+	}
+}
+
+// Reports come out in the same order whatever order the tests ran in
+func TestShuffledScanReportsInOrder(t *testing.T) {
+	srv := newRecordingServer(t, selftestHandler())
+	requests := []APIRequest{
+		getRequest(srv.URL + "/api/users/{user_id}"),
+		getRequest(srv.URL + "/api/users/{user_id}/orders"),
+	}
+
+	describe := func(seed int64) string {
+		s := fastScanner(selftestUsers(), requests)
+		s.SetShuffle(seed != 0, seed)
+		findings, err := s.Scan(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := []string{}
+		for _, f := range findings {
+			lines = append(lines, f.ID)
+		}
+		return strings.Join(lines, " ")
+	}
+
+	want := describe(0)
+	for _, seed := range []int64{1, 2, 3} {
+		if got := describe(seed); got != want {
+			t.Errorf("seed %d reported %s, want %s", seed, got, want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// defaultSiblingRange is how many IDs either side of a user's own ObjectId or
+// UUIDv1 are probed
+const defaultSiblingRange = 5
+
+// SetSiblingEnum enables probing up to n structural neighbours of each
+// ObjectId and UUIDv1 path ID (0 disables)
+func (s *Scanner) SetSiblingEnum(n int) {
+	s.siblings = n
+}
+
+// siblingIDs derives the n IDs either side of id by stepping the part that
+// increments between objects: the trailing 3-byte counter of a Mongo ObjectId,
+// or the time_low field of a version-1 UUID. Other IDs have no siblings.
+func siblingIDs(id IDPattern, n int) []string {
+	var prefix, field, suffix string
+	var bits uint
+
+	switch {
+	case id.Kind == "objectid":
+		prefix, field, bits = id.Value[:18], id.Value[18:], 24
+	case id.Kind == "uuid" && id.Value[14] == '1':
+		field, suffix, bits = id.Value[:8], id.Value[8:], 32
+	default:
+		return nil
+	}
+
+	base, err := strconv.ParseUint(field, 16, 64)
+	if err != nil {
+		return nil
+	}
+
+	mask := uint64(1)<<bits - 1
+	width := len(field)
+	ids := []string{}
+	for d := -n; d <= n; d++ {
+		if d == 0 {
+			continue
+		}
+		v := (base + uint64(int64(d))) & mask
+		ids = append(ids, fmt.Sprintf("%s%0*x%s", prefix, width, v, suffix))
+	}
+	return ids
+}
+
+// EnumerateSiblings probes the neighbours of each user's own ObjectId and
+// UUIDv1 path IDs and reports those that return another object
+func (s *Scanner) EnumerateSiblings(ctx context.Context, baselines BaselineMap) []Finding {
+	if s.siblings <= 0 {
+		return []Finding{}
+	}
+
+	return s.sweepIDs(ctx, baselines, idSweep{
+		kind:  kindSiblings,
+		label: fmt.Sprintf("±%d siblings", s.siblings),
+		candidates: func(id IDPattern) []string {
+			return siblingIDs(id, s.siblings)
+		},
+		describe: func(user User, n int, id IDPattern) string {
+			return fmt.Sprintf("User '%s' can access %d '%s' objects with IDs adjacent to their own", user.Name, n, id.Key)
+		},
+	})
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSiblingIDs(t *testing.T) {
+	for _, tc := range []struct {
+		id   IDPattern
+		want []string
+	}{
+		// The ObjectId counter wraps within its 3 bytes
+		{IDPattern{Kind: "objectid", Value: "507f1f77bcf86cd799ffffff"}, []string{"507f1f77bcf86cd799fffffe", "507f1f77bcf86cd799000000"}},
+		// UUIDv1 steps time_low and keeps the rest
+		{IDPattern{Kind: "uuid", Value: "0000000a-58cc-11ee-8c99-0242ac120002"}, []string{"00000009-58cc-11ee-8c99-0242ac120002", "0000000b-58cc-11ee-8c99-0242ac120002"}},
+		// Random UUIDs and numbers have no structural neighbours
+		{IDPattern{Kind: "uuid", Value: "3f2c8a51-9b7d-4e21-a0c4-5d8e6f1b2a90"}, nil},
+		{IDPattern{Kind: "numeric", Value: "123"}, nil},
+	} {
+		got := siblingIDs(tc.id, 1)
+		if strings.Join(got, " ") != strings.Join(tc.want, " ") {
+			t.Errorf("siblingIDs(%s) = %q, want %q", tc.id.Value, got, tc.want)
+		}
+	}
+}
+
+// Only the neighbour that holds another object is reported
+func TestEnumerateSiblings(t *testing.T) {
+	own := "507f1f77bcf86cd799439011"
+	docs := map[string]string{
+		own:                        "alice's notes",
+		"507f1f77bcf86cd799439013": "carol's tax return",
+	}
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, ok := docs[strings.TrimPrefix(r.URL.Path, "/api/documents/")]
+		if !ok || r.Header.Get("Authorization") == "" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"text":%q}`, doc)
+	}))
+	alice := User{Name: "alice", Headers: map[string]string{"Authorization": "Bearer a"}, Params: map[string]string{"document_id": own}}
+	s := fastScanner([]User{alice}, []APIRequest{getRequest(srv.URL + "/api/documents/{document_id}")})
+	s.SetSiblingEnum(3)
+
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var siblings []Finding
+	for _, f := range findings {
+		if strings.HasPrefix(f.Kind, kindSiblings) {
+			siblings = append(siblings, f)
+		}
+	}
+	if len(siblings) != 1 || !strings.HasSuffix(siblings[0].Evidence, "(±3 siblings): 507f1f77bcf86cd799439013") {
+		t.Fatalf("want one sibling finding for ...9013, got %+v", siblings)
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// defaultSimilarityThreshold is the score at or above which a body counts as
+// matching the baseline under a similarity metric
+const defaultSimilarityThreshold = 0.9
+
+// maxLevenshteinSize caps the bodies compared by edit distance, which is
+// quadratic; larger bodies are compared by token Jaccard instead
+const maxLevenshteinSize = 8 << 10
+
+// similarityMetrics score how alike two bodies are, from 0 (nothing shared)
+// to 1 (the same). "size" is not listed: it compares lengths against
+// --size-tolerance instead of scoring content.
+var similarityMetrics = map[string]func(a, b []byte) float64{
+	"jaccard":     jaccardSimilarity,
+	"levenshtein": levenshteinSimilarity,
+	"json":        jsonSimilarity,
+}
+
+// SetSimilarity selects how a cross-user body is matched against the
+// victim's baseline when it isn't identical: "size" (within the size
+// tolerance, the default) or one of jaccard, levenshtein and json with a
+// threshold between 0 and 1
+func (s *Scanner) SetSimilarity(metric string, threshold float64) error {
+	metric = strings.ToLower(metric)
+	if _, ok := similarityMetrics[metric]; !ok && metric != "size" {
+		return fmt.Errorf("unknown similarity %q (want size, jaccard, levenshtein or json)", metric)
+	}
+	if threshold < 0 || threshold > 1 {
+		return fmt.Errorf("similarity threshold must be between 0 and 1, got %g", threshold)
+	}
+	if metric == "size" {
+		metric = ""
+	}
+	s.similarity = metric
+	s.similarityThreshold = threshold
+	return nil
+}
+
+// matchesBaseline reports whether body is close enough to the baseline to
+// count as the same resource, with a note on the score for the evidence.
+// Baselines without a kept body (HEAD baselines, bodies over 1 MB) fall back
+// to the size comparison.
+func (s *Scanner) matchesBaseline(body []byte, baseline Baseline) (bool, string) {
+	metric, ok := similarityMetrics[s.similarity]
+	if !ok || baseline.Body == nil {
+		return abs(len(body)-baseline.BodySize) <= s.sizeTolerance, ""
+	}
+	score := metric(body, baseline.Body)
+	return score >= s.similarityThreshold, fmt.Sprintf("similarity %.2f (%s)", score, s.similarity)
+}
+
+// keepsBaselineBody reports whether baselines need their bodies in memory
+func (s *Scanner) keepsBaselineBody() bool {
+	return s.diff || s.similarity != ""
+}
+
+// jaccardSimilarity compares the sets of alphanumeric tokens in a and b
+func jaccardSimilarity(a, b []byte) float64 {
+	return jaccard(tokenSet(string(a)), tokenSet(string(b)))
+}
+
+func tokenSet(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, tok := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		set[tok] = true
+	}
+	return set
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	shared := 0
+	for k := range a {
+		if b[k] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// levenshteinSimilarity is 1 minus the byte edit distance over the longer
+// length. Bodies over maxLevenshteinSize use jaccardSimilarity.
+func levenshteinSimilarity(a, b []byte) float64 {
+	if len(a) > maxLevenshteinSize || len(b) > maxLevenshteinSize {
+		return jaccardSimilarity(a, b)
+	}
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(b)])/float64(longest)
+}
+
+// jsonSimilarity compares the structure of two JSON documents: the Jaccard
+// of their leaf paths, ignoring values. Two users' records of the same shape
+// score 1. Non-JSON bodies use jaccardSimilarity.
+func jsonSimilarity(a, b []byte) float64 {
+	fa, okA := flattenJSON(a)
+	fb, okB := flattenJSON(b)
+	if !okA || !okB {
+		return jaccardSimilarity(a, b)
+	}
+	pa := make(map[string]bool, len(fa))
+	for path := range fa {
+		pa[path] = true
+	}
+	pb := make(map[string]bool, len(fb))
+	for path := range fb {
+		pb[path] = true
+	}
+	return jaccard(pa, pb)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSimilarityMetrics(t *testing.T) {
+	alice := []byte(`{"id":123,"name":"Alice","email":"alice@example.com"}`)
+	bob := []byte(`{"id":456,"name":"Bob","email":"bob@example.com"}`)
+
+	if got := jsonSimilarity(alice, bob); got != 1 {
+		t.Errorf("jsonSimilarity of same-shaped records = %g, want 1", got)
+	}
+	if got := jsonSimilarity(alice, []byte(`{"error":"not found"}`)); got != 0 {
+		t.Errorf("jsonSimilarity of unrelated documents = %g, want 0", got)
+	}
+
+	// {a b c} vs {b c d}: 2 shared of 4
+	if got := jaccardSimilarity([]byte("a b c"), []byte("b, c; d")); got != 0.5 {
+		t.Errorf("jaccardSimilarity = %g, want 0.5", got)
+	}
+
+	// kitten → sitting is 3 edits over 7 bytes
+	if got, want := levenshteinSimilarity([]byte("kitten"), []byte("sitting")), 1-3.0/7; math.Abs(got-want) > 1e-9 {
+		t.Errorf("levenshteinSimilarity = %g, want %g", got, want)
+	}
+	big := []byte(strings.Repeat("x ", maxLevenshteinSize))
+	if got := levenshteinSimilarity(big, big); got != 1 {
+		t.Errorf("levenshteinSimilarity over the size cap = %g, want 1", got)
+	}
+
+	for name, metric := range similarityMetrics {
+		if got := metric(nil, nil); got != 1 {
+			t.Errorf("%s of two empty bodies = %g, want 1", name, got)
+		}
+	}
+}
+
+func TestSetSimilarity(t *testing.T) {
+	s := NewScanner(nil, nil)
+	if err := s.SetSimilarity("cosine", 0.9); err == nil {
+		t.Error("unknown metric accepted")
+	}
+	if err := s.SetSimilarity("json", 1.5); err == nil {
+		t.Error("threshold above 1 accepted")
+	}
+	if err := s.SetSimilarity("Size", 0.9); err != nil || s.similarity != "" {
+		t.Errorf("size: similarity = %q, err = %v", s.similarity, err)
+	}
+}
+
+// with a metric set, a body that differs in size but shares the baseline's
+// shape still matches; without one, the size tolerance decides
+func TestMatchesBaseline(t *testing.T) {
+	baseline := Baseline{
+		Body:     []byte(`{"id":456,"name":"Bob"}`),
+		BodySize: len(`{"id":456,"name":"Bob"}`),
+	}
+	body := []byte(`{"id":456,"name":"Bob the Builder, of 12 Long Street"}`)
+
+	s := NewScanner(nil, nil)
+	s.SetSizeTolerance(10)
+	if ok, _ := s.matchesBaseline(body, baseline); ok {
+		t.Error("size comparison matched a body well outside the tolerance")
+	}
+
+	if err := s.SetSimilarity("json", defaultSimilarityThreshold); err != nil {
+		t.Fatal(err)
+	}
+	ok, note := s.matchesBaseline(body, baseline)
+	if !ok || note != "similarity 1.00 (json)" {
+		t.Errorf("json: matched = %v, note = %q", ok, note)
+	}
+
+	// bodyless baselines (HEAD, over 1 MB) fall back to size
+	if ok, note := s.matchesBaseline(body, Baseline{BodySize: baseline.BodySize}); ok || note != "" {
+		t.Errorf("bodyless baseline: matched = %v, note = %q", ok, note)
+	}
+}
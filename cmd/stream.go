@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// streamableFormats can be written to --output as findings arrive.
+// Single-document formats (html) are only written once the scan completes.
+var streamableFormats = map[string]bool{
+	"json":  true,
+	"jsonl": true,
+	"csv":   true,
+}
+
+// findingStream appends findings to the output file while the scan runs, so
+// partial results survive a crash or interrupt
+type findingStream struct {
+	mu       sync.Mutex
+	path     string
+	format   string
+	file     *os.File
+	buf      *bufio.Writer
+	csv      *csv.Writer
+	findings []Finding
+	log      Logger
+}
+
+func openFindingStream(path, format string, log Logger) (*findingStream, error) {
+	if !streamableFormats[format] {
+		return nil, fmt.Errorf("format %q cannot be streamed", format)
+	}
+
+	fs := &findingStream{path: path, format: format, findings: []Finding{}, log: log}
+
+	if format == "json" {
+		// Rewritten whole on each finding so the file is always a valid document
+		return fs, fs.rewriteJSON()
+	}
+
+	// JSONL findings carry raw request credentials, so streams are owner-only
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	fs.file = file
+	fs.buf = bufio.NewWriter(file)
+
+	if format == "csv" {
+		fs.csv = csv.NewWriter(fs.buf)
+		fs.csv.Write(csvHeader)
+		fs.csv.Flush()
+	}
+
+	return fs, fs.flush()
+}
+
+// Write records a finding and flushes it to disk
+func (fs *findingStream) Write(f Finding) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.findings = append(fs.findings, f)
+
+	var err error
+	switch fs.format {
+	case "json":
+		err = fs.rewriteJSON()
+	case "jsonl":
+		data, _ := json.Marshal(f)
+		fs.buf.Write(data)
+		fs.buf.WriteString("\n")
+		err = fs.flush()
+	case "csv":
+		fs.csv.Write(csvRecord(f))
+		fs.csv.Flush()
+		err = fs.flush()
+	}
+
+	if err != nil {
+		fs.log.Warnf("⚠️  Failed to write finding to %s: %v\n", fs.path, err)
+	}
+}
+
+// Close writes the final document from the complete findings set
+func (fs *findingStream) Close(findings []Finding) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.findings = findings
+	if fs.format == "json" {
+		return fs.rewriteJSON()
+	}
+
+	if err := fs.flush(); err != nil {
+		fs.file.Close()
+		return err
+	}
+	return fs.file.Close()
+}
+
+func (fs *findingStream) flush() error {
+	if err := fs.buf.Flush(); err != nil {
+		return err
+	}
+	return fs.file.Sync()
+}
+
+// rewriteJSON atomically replaces the output file with the current findings
+func (fs *findingStream) rewriteJSON() error {
+	tmp := fs.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(formatJSON(fs.findings)), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fs.path)
+}
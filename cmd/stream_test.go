@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Each streamed finding is on disk before the scan ends, and the file is
+// owner-only since findings carry request credentials
+func TestFindingStreamWritesIncrementally(t *testing.T) {
+	for _, format := range []string{"json", "jsonl", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "findings."+format)
+			fs, err := openFindingStream(path, format, &recordingLogger{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			first := Finding{ID: "first", Severity: SeverityCritical, Endpoint: "/api/users/123", Method: "GET"}
+			fs.Write(first)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), "/api/users/123") {
+				t.Errorf("after one Write the file holds %q", data)
+			}
+			if format == "json" {
+				var parsed struct{ Findings []Finding }
+				if err := json.Unmarshal(data, &parsed); err != nil || len(parsed.Findings) != 1 {
+					t.Errorf("mid-scan JSON is not a valid one-finding document: %v %q", err, data)
+				}
+			}
+
+			second := Finding{ID: "second", Severity: SeverityHigh, Endpoint: "/api/orders/9", Method: "GET"}
+			fs.Write(second)
+			if err := fs.Close([]Finding{first, second}); err != nil {
+				t.Fatal(err)
+			}
+			data, _ = os.ReadFile(path)
+			if !strings.Contains(string(data), "/api/orders/9") {
+				t.Errorf("closed file holds %q", data)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := info.Mode().Perm(); perm != 0600 {
+				t.Errorf("file mode = %o, want 600", perm)
+			}
+		})
+	}
+}
+
+func TestFindingStreamRejectsHTML(t *testing.T) {
+	if _, err := openFindingStream(filepath.Join(t.TempDir(), "r.html"), "html", &recordingLogger{}); err == nil {
+		t.Error("html opened as a stream")
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Exit reasons reported by --summary-json
+const (
+	exitComplete     = "complete"      // every test ran
+	exitNewFindings  = "new_findings"  // findings not in --baseline-findings
+	exitInterrupted  = "interrupted"   // Ctrl+C
+	exitFindingLimit = "finding_limit" // --max-findings or --stop-on-critical
+	exitScanError    = "scan_error"    // the scan aborted (e.g. strict drift)
+	exitOutputError  = "output_error"  // findings could not be written
+)
+
+// scanSummary is the machine-readable end-of-scan summary
+type scanSummary struct {
+	Findings        int              `json:"findings"`
+	Known           int              `json:"known,omitempty"` // accepted via --baseline-findings
+	BySeverity      map[Severity]int `json:"by_severity"`
+	Requests        int64            `json:"requests"`
+	Failures        int64            `json:"failures"` // requests that got no response
+	BytesSent       int64            `json:"bytes_sent"`
+	BytesReceived   int64            `json:"bytes_received"`
+	DurationSeconds float64          `json:"duration_seconds"`
+	ExitCode        int              `json:"exit_code"`
+	Reason          string           `json:"reason"`
+	Error           string           `json:"error,omitempty"`
+}
+
+// newScanSummary totals findings by severity (every level, zeros included)
+// and attaches the traffic counters and run time
+func newScanSummary(findings []Finding, traffic TrafficStats, elapsed time.Duration) scanSummary {
+	summary := scanSummary{
+		Findings:        len(findings),
+		BySeverity:      make(map[Severity]int, len(severities)),
+		Requests:        traffic.Requests,
+		Failures:        traffic.Failures,
+		BytesSent:       traffic.BytesSent,
+		BytesReceived:   traffic.BytesReceived,
+		DurationSeconds: elapsed.Round(time.Millisecond).Seconds(),
+	}
+	for _, sev := range severities {
+		summary.BySeverity[sev] = 0
+	}
+	for _, f := range findings {
+		summary.BySeverity[f.Severity]++
+	}
+	return summary
+}
+
+// writeSummaryJSON writes the summary as one line of JSON
+func writeSummaryJSON(w io.Writer, summary scanSummary, code int, reason string, err error) {
+	summary.ExitCode = code
+	summary.Reason = reason
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	data, _ := json.Marshal(summary)
+	fmt.Fprintln(w, string(data))
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// Failures counts every send that got no response, not the request_error
+// findings (which are deduplicated per endpoint and error class)
+func TestSummaryCountsFailedSends(t *testing.T) {
+	srv := newRecordingServer(t, http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	requests := []APIRequest{getRequest(url + "/api/users/123"), getRequest(url + "/api/users/456")}
+	s := fastScanner(selftestUsers(), requests)
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary := newScanSummary(findings, s.Traffic(), 0)
+	if summary.Requests != 0 || summary.Failures != 4 {
+		t.Errorf("requests = %d, failures = %d; want 0 and 4 (2 requests × 2 users)", summary.Requests, summary.Failures)
+	}
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRetryAfter is used when a 429 storm carries no Retry-After header
+	defaultRetryAfter = 30 * time.Second
+	// maxRetryAfter caps how long a single pause may last
+	maxRetryAfter = 10 * time.Minute
+	// maxRetries429 is how many times send retries a request that got a 429
+	maxRetries429 = 3
+)
+
+// retryBackoff is the wait before a 429 retry without Retry-After, per attempt
+var retryBackoff = time.Second
+
+// throttle pauses every worker once the target returns too many 429s in a row.
+// The counter is shared across workers, so the whole scan backs off together.
+type throttle struct {
+	mu          sync.Mutex
+	threshold   int           // consecutive 429s that trigger a pause (0 disables)
+	consecutive int           // 429s seen since the last non-429 response
+	longest     time.Duration // largest Retry-After seen in the current run of 429s
+	pauseUntil  time.Time
+	log         Logger
+}
+
+// SetPauseOn429 pauses the scan after n consecutive 429 responses (0 disables)
+func (s *Scanner) SetPauseOn429(n int) {
+	s.throttle.mu.Lock()
+	defer s.throttle.mu.Unlock()
+	s.throttle.threshold = n
+}
+
+// enabled reports whether 429 pauses (and with them retries) are on
+func (t *throttle) enabled() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.threshold > 0
+}
+
+// wait blocks while a scan-wide pause is in effect, or until the scan is cancelled
+func (t *throttle) wait(ctx context.Context) error {
+	t.mu.Lock()
+	until := t.pauseUntil
+	t.mu.Unlock()
+
+	return sleepContext(ctx, time.Until(until))
+}
+
+// retryDelay is how long to wait before retrying a request that got resp, a
+// 429, on its attempt'th try. A scan-wide pause in effect is waited out by
+// wait, so it adds nothing here.
+func (t *throttle) retryDelay(resp *http.Response, attempt int) time.Duration {
+	t.mu.Lock()
+	paused := time.Now().Before(t.pauseUntil)
+	t.mu.Unlock()
+
+	if paused {
+		return 0
+	}
+	if d := parseRetryAfter(resp.Header.Get("Retry-After")); d > 0 {
+		return d
+	}
+	return time.Duration(attempt) * retryBackoff
+}
+
+// sleepContext sleeps for d, returning early with ctx's error if it is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// observe records a response status and starts a pause when the threshold is hit
+func (t *throttle) observe(resp *http.Response) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.consecutive = 0
+		t.longest = 0
+		return
+	}
+
+	t.consecutive++
+	if d := parseRetryAfter(resp.Header.Get("Retry-After")); d > t.longest {
+		t.longest = d
+	}
+
+	if t.threshold <= 0 || t.consecutive < t.threshold || time.Now().Before(t.pauseUntil) {
+		return
+	}
+
+	pause := t.longest
+	if pause == 0 {
+		pause = defaultRetryAfter
+	}
+	t.pauseUntil = time.Now().Add(pause)
+
+	t.log.Warnf("⏸️  %d consecutive 429 responses; pausing all workers for %s\n", t.consecutive, pause)
+
+	t.consecutive = 0
+	t.longest = 0
+}
+
+// parseRetryAfter reads a Retry-After header as seconds or an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	var d time.Duration
+	if secs, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		d = time.Until(at)
+	}
+
+	if d < 0 {
+		return 0
+	}
+	if d > maxRetryAfter {
+		return maxRetryAfter
+	}
+	return d
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSendRetries429(t *testing.T) {
+	defer func(d time.Duration) { retryBackoff = d }(retryBackoff)
+	retryBackoff = time.Millisecond
+
+	var calls atomic.Int32
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+
+	s := fastScanner(nil, nil)
+	s.SetPauseOn429(5)
+	req := s.buildRequest(APIRequest{Method: "POST", URL: srv.URL, Body: `{"a":1}`}, User{}, nil)
+	resp, err := s.send(s.client, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200 after two retried 429s", resp.StatusCode)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("server got %d requests, want 3", n)
+	}
+}
+
+func TestSendDoesNotRetryWhenPausingIsOff(t *testing.T) {
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	s := fastScanner(nil, nil)
+	s.SetPauseOn429(0)
+	resp, err := s.send(s.client, s.buildRequest(getRequest(srv.URL), User{}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if n := len(srv.requests()); n != 1 {
+		t.Errorf("server got %d requests, want 1", n)
+	}
+}
+
+// Cancelling the scan must end a long 429 pause, and the throttled tests
+// must not be checkpointed as done
+func TestCancelInterruptsPause(t *testing.T) {
+	for _, workers := range []int{1, 2} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			// The two baselines are answered; every test after them is throttled
+			inner := selftestHandler()
+			var calls atomic.Int32
+			srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) > 2 {
+					w.Header().Set("Retry-After", "600")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				inner.ServeHTTP(w, r)
+			}))
+
+			s := fastScanner(selftestUsers(), []APIRequest{getRequest(srv.URL + "/api/users/123")})
+			s.SetWorkers(workers)
+			s.SetPauseOn429(1)
+			if _, err := s.SetCheckpoint(filepath.Join(t.TempDir(), "scan.ckpt")); err != nil {
+				t.Fatal(err)
+			}
+			s.OnFinding(func(Finding) {})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				_, err := s.Scan(ctx)
+				done <- err
+			}()
+
+			time.Sleep(300 * time.Millisecond)
+			cancel()
+			select {
+			case err := <-done:
+				if err != nil && !errors.Is(err, context.Canceled) {
+					t.Fatal(err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Scan still paused 5s after cancel")
+			}
+
+			for key := range s.checkpoint.done {
+				t.Errorf("test %q checkpointed as done though it only got 429s", key)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultLargeResponse is the body size above which a response is reported
+const defaultLargeResponse = 10 << 20
+
+// TrafficStats totals what a scan sent and received. Byte counts cover
+// request and response bodies, not headers. Requests counts responses;
+// Failures counts sends that got none (connection, TLS, timeout errors).
+type TrafficStats struct {
+	Requests      int64
+	Failures      int64
+	BytesSent     int64
+	BytesReceived int64
+}
+
+// traffic accumulates TrafficStats across workers and remembers the largest
+// response seen per endpoint
+type traffic struct {
+	requests atomic.Int64
+	failures atomic.Int64
+	sent     atomic.Int64
+	received atomic.Int64
+
+	mu    sync.Mutex
+	large map[string]largeResponse // endpoint -> largest response over the threshold
+}
+
+type largeResponse struct {
+	req      APIRequest
+	size     int
+	attacker string
+	victim   string
+}
+
+// countingBody counts response bytes as they are read
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (b countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+// SetLargeResponse sets the body size, in bytes, above which a response is
+// reported as an INFO finding (0 = off)
+func (s *Scanner) SetLargeResponse(bytes int) {
+	s.largeResponse = bytes
+}
+
+// Traffic returns the requests and body bytes sent and received so far
+func (s *Scanner) Traffic() TrafficStats {
+	return TrafficStats{
+		Requests:      s.traffic.requests.Load(),
+		Failures:      s.traffic.failures.Load(),
+		BytesSent:     s.traffic.sent.Load(),
+		BytesReceived: s.traffic.received.Load(),
+	}
+}
+
+// countRequest records an outgoing request and wraps resp to count the bytes read
+func (s *Scanner) countRequest(req *http.Request, resp *http.Response) {
+	s.traffic.requests.Add(1)
+	if req.ContentLength > 0 {
+		s.traffic.sent.Add(req.ContentLength)
+	}
+	resp.Body = countingBody{resp.Body, &s.traffic.received}
+}
+
+// countFailure records a send that got no response. Requests cut short by
+// the run ending (Ctrl+C, a finding limit) are not failures.
+func (s *Scanner) countFailure(ctx context.Context) {
+	if ctx.Err() == nil {
+		s.traffic.failures.Add(1)
+	}
+}
+
+// noteResponseSize remembers responses over the large-response threshold,
+// keeping the largest per endpoint. An unpaginated dump is worth a look on
+// its own, and more so when it answered a cross-user request.
+func (s *Scanner) noteResponseSize(req APIRequest, attacker, victim string, size int) {
+	if s.largeResponse <= 0 || size <= s.largeResponse {
+		return
+	}
+
+	endpoint := fmt.Sprintf("%s %s", req.Method, req.URL)
+
+	s.traffic.mu.Lock()
+	defer s.traffic.mu.Unlock()
+	if s.traffic.large == nil {
+		s.traffic.large = make(map[string]largeResponse)
+	}
+	// On a tie, prefer blaming the cross-user request over the user's own baseline
+	prev, ok := s.traffic.large[endpoint]
+	if !ok || size > prev.size || size == prev.size && victim != "" && prev.victim == "" {
+		s.traffic.large[endpoint] = largeResponse{req: req, size: size, attacker: attacker, victim: victim}
+	}
+}
+
+// largeResponseFindings turns the recorded large responses into INFO findings
+// and clears them for the next run
+func (s *Scanner) largeResponseFindings() []Finding {
+	s.traffic.mu.Lock()
+	large := s.traffic.large
+	s.traffic.large = nil
+	s.traffic.mu.Unlock()
+
+	endpoints := make([]string, 0, len(large))
+	for endpoint := range large {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	findings := []Finding{}
+	for _, endpoint := range endpoints {
+		lr := large[endpoint]
+		who := fmt.Sprintf("as '%s'", lr.attacker)
+		switch {
+		case lr.attacker == "":
+			who = "without auth"
+		case lr.victim != "":
+			who = fmt.Sprintf("'%s' requesting '%s's resource", lr.attacker, lr.victim)
+		}
+		findings = append(findings, Finding{
+			Kind:        kindLargeResponse,
+			Severity:    SeverityInfo,
+			Endpoint:    lr.req.URL,
+			Method:      lr.req.Method,
+			Description: "Unusually large response; check for an unpaginated or full-dataset download",
+			Evidence:    fmt.Sprintf("Largest response: %s (%s), threshold %s", formatBytes(int64(lr.size)), who, formatBytes(int64(s.largeResponse))),
+			Timestamp:   time.Now(),
+			Attacker:    lr.attacker,
+			Victim:      lr.victim,
+		})
+	}
+	return findings
+}
+
+// formatBytes renders a byte count for humans (1.5 MB)
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Traffic totals count every response and the body bytes each way, and a
+// response over the threshold becomes one INFO finding per endpoint
+func TestTrafficAndLargeResponses(t *testing.T) {
+	big := strings.Repeat("x", 4096)
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/export") {
+			w.Write([]byte(big))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	post := APIRequest{Method: "POST", URL: srv.URL + "/api/users/{user_id}/notes", Body: `{"text":"hi"}`, Headers: make(http.Header), Params: map[string]string{}}
+	requests := []APIRequest{getRequest(srv.URL + "/api/users/{user_id}/export"), post}
+
+	s := fastScanner(selftestUsers(), requests)
+	s.SetLargeResponse(1024)
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := s.Traffic()
+	sent := len(srv.requests())
+	if stats.Requests != int64(sent) || stats.Failures != 0 {
+		t.Errorf("traffic = %+v, server saw %d requests", stats, sent)
+	}
+	if stats.BytesReceived < int64(4*len(big)) {
+		t.Errorf("received %d bytes, want at least the export's 2 baselines and 2 cross-user tests", stats.BytesReceived)
+	}
+	if stats.BytesSent == 0 || stats.BytesSent%int64(len(post.Body)) != 0 {
+		t.Errorf("sent %d bytes, want a multiple of the %d-byte POST body", stats.BytesSent, len(post.Body))
+	}
+
+	large := 0
+	for _, f := range findings {
+		if f.Kind == kindLargeResponse {
+			large++
+			if f.Severity != SeverityInfo || f.Endpoint != requests[0].URL {
+				t.Errorf("large-response finding = %+v", f)
+			}
+		}
+	}
+	if large != 1 {
+		t.Errorf("%d large-response findings, want one for the export", large)
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+)
+
+// defaultMaxIdleConns is the pool-wide idle connection floor; the per-host
+// idle pool is sized to the worker count unless set explicitly
+const defaultMaxIdleConns = 100
+
+// newTransport clones http.DefaultTransport (dial and TLS handshake timeouts,
+// HTTP/2) so the scanner can tune its pool without touching the global one.
+// Until SetProxy overrides it, the proxy comes from HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY.
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	return t
+}
+
+// envProxy returns the proxy configured in the environment, if any
+func envProxy() string {
+	for _, key := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// transport returns the scanner's *http.Transport, installing a fresh one if
+// SetTransport replaced it with some other RoundTripper
+func (s *Scanner) transport() *http.Transport {
+	if t, ok := s.client.Transport.(*http.Transport); ok {
+		return t
+	}
+	t := newTransport()
+	s.client.Transport = t
+	return t
+}
+
+// SetConnPool sizes the connection pool: maxIdle idle connections kept open
+// per host and maxPerHost connections per host in total. Zero keeps the
+// defaults: an idle pool sized to the worker count, no per-host cap.
+func (s *Scanner) SetConnPool(maxIdle, maxPerHost int) {
+	s.maxIdleConns = maxIdle
+	s.maxConnsPerHost = maxPerHost
+}
+
+// tuneConnPool applies the pool settings before a scan. Without it the
+// stdlib keeps only 2 idle connections per host, so every extra worker
+// reconnects on each request.
+func (s *Scanner) tuneConnPool() {
+	t, ok := s.client.Transport.(*http.Transport)
+	if !ok {
+		return // a custom RoundTripper manages its own connections
+	}
+
+	idle := s.maxIdleConns
+	if idle <= 0 {
+		idle = s.workers + 1 // the no-auth and OAuth requests share the pool
+	}
+	t.MaxIdleConnsPerHost = idle
+	t.MaxIdleConns = max(defaultMaxIdleConns, idle)
+	t.MaxConnsPerHost = s.maxConnsPerHost
+}
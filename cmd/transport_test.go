@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTuneConnPool(t *testing.T) {
+	for _, tc := range []struct {
+		workers, maxIdle, maxPerHost int
+		wantIdle, wantTotal          int
+	}{
+		{8, 0, 0, 9, defaultMaxIdleConns},
+		{8, 200, 16, 200, 200},
+		{0, 0, 0, 2, defaultMaxIdleConns}, // sequential: one worker
+	} {
+		s := NewScanner(nil, nil)
+		s.SetWorkers(tc.workers)
+		s.SetConnPool(tc.maxIdle, tc.maxPerHost)
+		s.tuneConnPool()
+
+		tr := s.transport()
+		if tr.MaxIdleConnsPerHost != tc.wantIdle || tr.MaxIdleConns != tc.wantTotal || tr.MaxConnsPerHost != tc.maxPerHost {
+			t.Errorf("workers=%d idle=%d perHost=%d: transport has %d per host, %d total, cap %d",
+				tc.workers, tc.maxIdle, tc.maxPerHost, tr.MaxIdleConnsPerHost, tr.MaxIdleConns, tr.MaxConnsPerHost)
+		}
+	}
+}
+
+// Workers reuse kept-alive connections instead of dialing per request
+func TestScanReusesConnections(t *testing.T) {
+	var dials atomic.Int32
+	srv := httptest.NewUnstartedServer(selftestHandler())
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			dials.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	requests := []APIRequest{}
+	for i := 0; i < 20; i++ {
+		requests = append(requests, getRequest(fmt.Sprintf("%s/api/users/{user_id}?page=%d", srv.URL, i)))
+	}
+	s := fastScanner(selftestUsers(), requests)
+	s.SetWorkers(4)
+	if _, err := s.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 20 endpoints × (2 baselines + 2 cross-user tests + 1 no-auth test)
+	if n := dials.Load(); n > 10 {
+		t.Errorf("opened %d connections for 100 requests with 4 workers", n)
+	}
+}
+
+func TestEnvProxy(t *testing.T) {
+	for _, key := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		t.Setenv(key, "")
+	}
+	if got := envProxy(); got != "" {
+		t.Errorf("envProxy() = %q with no proxy set", got)
+	}
+	t.Setenv("http_proxy", "http://plain:3128")
+	t.Setenv("HTTPS_PROXY", "http://secure:3128")
+	if got := envProxy(); got != "http://secure:3128" {
+		t.Errorf("envProxy() = %q, want HTTPS_PROXY first", got)
+	}
+}
+
+// --proxy-user works with a proxy from the environment, but needs one
+func TestProxyUserNeedsSomeProxy(t *testing.T) {
+	resetFlags(t)
+	for _, key := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		t.Setenv(key, "")
+	}
+	proxyUser, proxyPass = "burp", "s3cret"
+
+	if err := configureNetwork(NewScanner(nil, nil), nil); err == nil {
+		t.Error("--proxy-user accepted without any proxy")
+	}
+	t.Setenv("HTTPS_PROXY", "http://127.0.0.1:8080")
+	if err := configureNetwork(NewScanner(nil, nil), nil); err != nil {
+		t.Errorf("--proxy-user with HTTPS_PROXY: %v", err)
+	}
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// typeVariant re-types an ID value. json renders it for paths and JSON
+// bodies; query rewrites a key=value pair in the bracket syntax that PHP,
+// Rails and Express (qs) parse into arrays and objects.
+type typeVariant struct {
+	name  string
+	json  func(v string) interface{}
+	query func(key, v string) (string, string)
+}
+
+var typeVariants = []typeVariant{
+	{
+		name:  "array",
+		json:  func(v string) interface{} { return []interface{}{jsonScalar(v)} },
+		query: func(key, v string) (string, string) { return key + "[]", v },
+	},
+	{
+		name:  "object",
+		json:  func(v string) interface{} { return map[string]interface{}{"id": jsonScalar(v)} },
+		query: func(key, v string) (string, string) { return key + "[id]", v },
+	},
+	{
+		name: "string",
+		json: func(v string) interface{} {
+			// Numbers become strings; strings become numbers where they can
+			if isNumericID(v) {
+				return v
+			}
+			return jsonScalar(v)
+		},
+		query: func(key, v string) (string, string) { return key, `"` + v + `"` },
+	},
+	{
+		name: "nosql",
+		json: func(v string) interface{} {
+			if isNumericID(v) {
+				return map[string]interface{}{"$gt": 0}
+			}
+			return map[string]interface{}{"$gt": ""}
+		},
+		query: func(key, v string) (string, string) {
+			if isNumericID(v) {
+				return key + "[$gt]", "0"
+			}
+			return key + "[$gt]", ""
+		},
+	},
+}
+
+// typeFuzzSkipMethods are never re-typed: an operator like $gt can match
+// every record, which on a write means every record is changed. POST is
+// skipped too unless SetTypeFuzzPost allows it.
+var typeFuzzSkipMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// typeFuzzLocations names each target location in finding descriptions
+var typeFuzzLocations = map[string]string{
+	"path":  "path ID",
+	"query": "query parameter",
+	"body":  "body field",
+}
+
+// SetTypeFuzz enables the aggressive ID type-confusion test
+func (s *Scanner) SetTypeFuzz(enabled bool) {
+	s.typeFuzz = enabled
+}
+
+// SetTypeFuzzPost lets the type-confusion test re-type POST requests, whose
+// operator bodies can create or match many records
+func (s *Scanner) SetTypeFuzzPost(enabled bool) {
+	s.fuzzPost = enabled
+}
+
+// skipsTypeFuzz reports whether requests with method are never re-typed
+func (s *Scanner) skipsTypeFuzz(method string) bool {
+	method = strings.ToUpper(method)
+	return typeFuzzSkipMethods[method] && !(method == http.MethodPost && s.fuzzPost)
+}
+
+// jsonScalar returns v as a JSON number when it is numeric
+func jsonScalar(v string) interface{} {
+	if isNumericID(v) {
+		return json.Number(v)
+	}
+	return v
+}
+
+// typeFuzzTarget is one ID in a personalized request and how to re-type it
+type typeFuzzTarget struct {
+	location string // "path", "query" or "body"
+	key      string
+	value    string
+	apply    func(v typeVariant) (string, string) // url, body
+}
+
+// typeFuzzTargets finds the user's IDs in a personalized request: path IDs,
+// plus query parameters and top-level JSON body fields holding one of the
+// user's param values
+func typeFuzzTargets(rawURL, body string, user User) []typeFuzzTarget {
+	owned := map[string]bool{}
+	for _, v := range user.Params {
+		if v != "" {
+			owned[v] = true
+		}
+	}
+
+	targets := []typeFuzzTarget{}
+
+	for _, id := range ExtractIDsFromURL(rawURL) {
+		if id.Kind == "placeholder" {
+			continue
+		}
+		id := id
+		targets = append(targets, typeFuzzTarget{
+			location: "path",
+			key:      id.Key,
+			value:    id.Value,
+			apply: func(v typeVariant) (string, string) {
+				raw, _ := json.Marshal(v.json(id.Value))
+				return replaceIDSegment(rawURL, id, url.PathEscape(string(raw))), body
+			},
+		})
+	}
+
+	if u, err := url.Parse(rawURL); err == nil {
+		query := u.Query()
+		for key, values := range query {
+			if len(values) != 1 || !owned[values[0]] {
+				continue
+			}
+			key, value := key, values[0]
+			targets = append(targets, typeFuzzTarget{
+				location: "query",
+				key:      key,
+				value:    value,
+				apply: func(v typeVariant) (string, string) {
+					q := u.Query()
+					q.Del(key)
+					k, val := v.query(key, value)
+					q.Set(k, val)
+					fuzzed := *u
+					fuzzed.RawQuery = q.Encode()
+					return fuzzed.String(), body
+				},
+			})
+		}
+	}
+
+	var doc map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	if body != "" && dec.Decode(&doc) == nil {
+		for key, field := range doc {
+			value := fmt.Sprint(field)
+			switch field.(type) {
+			case string, json.Number:
+			default:
+				continue
+			}
+			if !owned[value] {
+				continue
+			}
+			key := key
+			targets = append(targets, typeFuzzTarget{
+				location: "body",
+				key:      key,
+				value:    value,
+				apply: func(v typeVariant) (string, string) {
+					fuzzed := make(map[string]interface{}, len(doc))
+					for k, f := range doc {
+						fuzzed[k] = f
+					}
+					fuzzed[key] = v.json(value)
+					raw, _ := json.Marshal(fuzzed)
+					return rawURL, string(raw)
+				},
+			})
+		}
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].location != targets[j].location {
+			return targets[i].location < targets[j].location
+		}
+		return targets[i].key < targets[j].key
+	})
+	return targets
+}
+
+// broaderThanBaseline explains why a re-typed request's 2xx response carries
+// more data than the user's own baseline, or returns "" when it doesn't. The
+// bool reports whether the extra data is certainly someone else's (foreign
+// list items or another user's sensitive values) rather than just more bytes.
+func (s *Scanner) broaderThanBaseline(body []byte, own Baseline, others map[string]Baseline) (string, bool) {
+	if ids, isList := extractItemIDs(body); isList && len(ids) > 0 {
+		ownItems := make(map[string]bool, len(own.ItemIDs))
+		for _, id := range own.ItemIDs {
+			ownItems[id] = true
+		}
+		foreign := []string{}
+		for _, id := range ids {
+			if !ownItems[id] {
+				foreign = append(foreign, id)
+			}
+		}
+		if len(foreign) > 0 && (len(own.ItemIDs) > 0 || len(ids) > 1) {
+			return fmt.Sprintf("%d items not in the user's own response: %s", len(foreign), summarizeIDs(foreign)), true
+		}
+	}
+
+	names := make([]string, 0, len(others))
+	for name := range others {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if leaked := leakedFields(others[name].Sensitive, own.Sensitive, body); len(leaked) > 0 {
+			return fmt.Sprintf("'%s's values present for: %s", name, summarizeIDs(leaked)), true
+		}
+	}
+
+	if !own.HeadOnly && len(body) > 2*own.BodySize && len(body)-own.BodySize > s.sizeTolerance {
+		return fmt.Sprintf("%d bytes against a %d-byte baseline", len(body), own.BodySize), false
+	}
+	return "", false
+}
+
+// FuzzIDTypes replays each endpoint as each user with their own IDs re-typed
+// (array, object, string, NoSQL operator) and reports IDs where a variant
+// returned more data than the user's baseline. Writes are skipped; POST
+// only runs with SetTypeFuzzPost.
+func (s *Scanner) FuzzIDTypes(ctx context.Context, baselines BaselineMap) []Finding {
+	findings := []Finding{}
+	if !s.typeFuzz {
+		return findings
+	}
+
+	for _, req := range s.Requests {
+		if s.skipsTypeFuzz(req.Method) {
+			continue
+		}
+		endpoint := fmt.Sprintf("%s %s", req.Method, req.URL)
+
+		for _, user := range s.Users {
+			own, ok := baselines[endpoint][user.Name]
+			if !ok || own.StatusCode != 200 || user.CanAttack != nil && !*user.CanAttack {
+				continue
+			}
+
+			others := map[string]Baseline{}
+			for name, b := range baselines[endpoint] {
+				if name != user.Name && !b.Denied {
+					others[name] = b
+				}
+			}
+
+			rawURL, body := s.personalize(req, user)
+
+			for _, target := range typeFuzzTargets(rawURL, body, user) {
+				s.log.Debugf("🧪 Type fuzzing %s (%s %s) as %s\n", endpoint, target.location, target.key, user.Name)
+
+				var hit *Finding
+				broader := []string{}
+				severity := SeverityMedium
+				for _, variant := range typeVariants {
+					if ctx.Err() != nil {
+						return findings
+					}
+
+					fuzzURL, fuzzBody := target.apply(variant)
+					testReq := s.buildRequest(APIRequest{
+						Method:  req.Method,
+						URL:     fuzzURL,
+						Headers: req.Headers,
+						Body:    fuzzBody,
+					}, user, nil)
+					if testReq == nil {
+						continue
+					}
+
+					resp, err := s.executeAs(user, testReq.WithContext(ctx))
+					if err == nil {
+						respBody, _ := s.readBody(resp)
+						resp.Body.Close()
+
+						if resp.StatusCode/100 == 2 && hashBody(respBody) != own.BodyHash {
+							if why, foreign := s.broaderThanBaseline(respBody, own, others); why != "" {
+								s.log.Debugf("   🔓 %s variant: %s\n", variant.name, why)
+								broader = append(broader, fmt.Sprintf("%s (%s)", variant.name, why))
+								if foreign {
+									severity = SeverityHigh
+								}
+								if hit == nil {
+									hit = withExchange(&Finding{}, testReq, respBody)
+								}
+							}
+						}
+					}
+
+					select {
+					case <-ctx.Done():
+						return findings
+					case <-time.After(s.rateDelay):
+					}
+				}
+
+				if hit == nil {
+					continue
+				}
+
+				hit.Kind = findingKind(kindTypeConfusion, target.location, target.key)
+				hit.Severity = severity
+				hit.Endpoint = req.URL
+				hit.Method = req.Method
+				hit.Description = fmt.Sprintf("ID type confusion: re-typing the '%s' %s returned more data than '%s's own request",
+					target.key, typeFuzzLocations[target.location], user.Name)
+				hit.Evidence = fmt.Sprintf("Baseline value %s; broader variants: %s", target.value, strings.Join(broader, "; "))
+				hit.Timestamp = time.Now()
+				hit.Attacker = user.Name
+				findings = s.addFinding(findings, *hit)
+			}
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTypeFuzzSkipsPostByDefault(t *testing.T) {
+	for _, allowPost := range []bool{false, true} {
+		var operatorBodies int
+		srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), "$gt") {
+				operatorBodies++
+			}
+			fmt.Fprintf(w, `{"owner":%q}`, r.Header.Get("Authorization"))
+		}))
+
+		req := APIRequest{Method: "POST", URL: srv.URL + "/api/search", Headers: make(http.Header), Body: `{"user_id":"123"}`}
+		s := fastScanner(selftestUsers(), []APIRequest{req})
+		s.SetTypeFuzz(true)
+		s.SetTypeFuzzPost(allowPost)
+		if _, err := s.Scan(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		if allowPost && operatorBodies == 0 {
+			t.Error("SetTypeFuzzPost(true): no POST body was re-typed")
+		}
+		if !allowPost && operatorBodies > 0 {
+			t.Errorf("sent %d POST bodies with $gt without SetTypeFuzzPost", operatorBodies)
+		}
+	}
+}
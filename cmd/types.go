@@ -1,41 +1,207 @@
 package cmd
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 )
 
 // User represents a user context for testing
 type User struct {
-	Name    string            `json:"name"`
+	Name        string            `json:"name"`
+	Headers     map[string]string `json:"headers"`
+	Params      map[string]string `json:"params"`
+	AuthParams  map[string]string `json:"auth_params,omitempty"`   // query-string credentials, e.g. api_key
+	Cookies     map[string]string `json:"cookies,omitempty"`       // session cookies, sent as one Cookie header
+	Credentials []CredentialSet   `json:"credentials,omitempty"`   // extra tokens tried when attacking
+	CanAttack   *bool             `json:"can_attack,omitempty"`    // default true
+	CanBeVictim *bool             `json:"can_be_victim,omitempty"` // default true
+	OAuth       *OAuthConfig      `json:"oauth,omitempty"`         // client-credentials token source
+
+	credential string // label of the credential set in use, if any
+}
+
+// CredentialSet is one of several tokens a user holds (e.g. different scopes)
+type CredentialSet struct {
+	Label   string            `json:"label"`
 	Headers map[string]string `json:"headers"`
-	Params  map[string]string `json:"params"`
 }
 
-// APIRequest represents a single API request to test
+// IsAnonymous reports whether the user carries no credentials at all. Such a
+// user acts as the guest context: it attacks other users but is never a victim.
+func (u User) IsAnonymous() bool {
+	return len(u.Headers) == 0 && len(u.AuthParams) == 0 && len(u.Credentials) == 0 && len(u.Cookies) == 0 && u.OAuth == nil
+}
+
+// canTest reports whether attacker should be tried against victim's resources,
+// honoring the users' can_attack / can_be_victim roles
+func canTest(attacker, victim User) bool {
+	if attacker.Name == victim.Name || victim.IsAnonymous() {
+		return false
+	}
+	if attacker.CanAttack != nil && !*attacker.CanAttack {
+		return false
+	}
+	return victim.CanBeVictim == nil || *victim.CanBeVictim
+}
+
+// normalizeUsers gives users that only define credential sets a primary
+// header set (the first one), used for baselines and as a victim
+func normalizeUsers(users []User) []User {
+	for i, u := range users {
+		if len(u.Headers) == 0 && len(u.Credentials) > 0 {
+			users[i].Headers = u.Credentials[0].Headers
+		}
+	}
+	return users
+}
+
+// defaultMaxCredentials caps how many credential sets each attacker tries
+const defaultMaxCredentials = 3
+
+// SetMaxCredentials caps the credential sets tried per attacker (0 = no cap)
+func (s *Scanner) SetMaxCredentials(n int) {
+	s.maxCredentials = n
+}
+
+// attackers expands every user into one attacker context per credential set
+// (see credentialContexts), up to the --max-credentials cap. Users without
+// credential sets attack with their primary headers only.
+func (s *Scanner) attackers() []User {
+	contexts := []User{}
+	for _, u := range s.Users {
+		if len(u.Credentials) == 0 {
+			contexts = append(contexts, u)
+			continue
+		}
+
+		for _, set := range s.cappedCredentials(u) {
+			ctx := u
+			ctx.Headers = set.Headers
+			ctx.credential = set.Label
+			contexts = append(contexts, ctx)
+		}
+	}
+	return contexts
+}
+
+// cappedCredentials is the user's credential contexts cut to the cap
+func (s *Scanner) cappedCredentials(u User) []CredentialSet {
+	sets := credentialContexts(u)
+	if s.maxCredentials > 0 && len(sets) > s.maxCredentials {
+		sets = sets[:s.maxCredentials]
+	}
+	return sets
+}
+
+// warnCredentialCap warns once per user whose credential sets exceed the cap
+func (s *Scanner) warnCredentialCap() {
+	for _, u := range s.Users {
+		if n := len(credentialContexts(u)); len(u.Credentials) > 0 && s.maxCredentials > 0 && n > s.maxCredentials {
+			s.log.Warnf("⚠️  User '%s' has %d credential sets; only the first %d are tried (see --max-credentials)\n",
+				u.Name, n, s.maxCredentials)
+		}
+	}
+}
+
+// attackerLabel names the attacker in finding descriptions
+func attackerLabel(u User) string {
+	label := fmt.Sprintf("User '%s'", u.Name)
+	if u.IsAnonymous() {
+		label = fmt.Sprintf("Anonymous user '%s'", u.Name)
+	}
+	if u.credential != "" {
+		label += fmt.Sprintf(" (credential '%s')", u.credential)
+	}
+	return label
+}
+
+// APIRequest represents a single API request to test.
+// Headers is a multi-map so repeated headers (Set-Cookie, X-Forwarded-For) survive parsing.
 type APIRequest struct {
 	Method  string
 	URL     string
-	Headers map[string]string
+	Headers http.Header
 	Body    string
 	Params  map[string]string
 }
 
 // Finding represents a potential security issue
 type Finding struct {
-	Severity    string    `json:"severity"`
-	Endpoint    string    `json:"endpoint"`
-	Method      string    `json:"method"`
-	Description string    `json:"description"`
-	Evidence    string    `json:"evidence"`
-	Timestamp   time.Time `json:"timestamp"`
+	ID          string           `json:"id"`             // stable across scans (see findingID)
+	Kind        string           `json:"kind,omitempty"` // what the test probed (see findingKind); part of the ID
+	Severity    Severity         `json:"severity"`
+	Endpoint    string           `json:"endpoint"`
+	Method      string           `json:"method"`
+	Description string           `json:"description"`
+	Evidence    string           `json:"evidence"`
+	Timestamp   time.Time        `json:"timestamp"`
+	Request     *RecordedRequest `json:"request,omitempty"`
+	Response    string           `json:"response_snippet,omitempty"`
+	Attacker    string           `json:"attacker,omitempty"`
+	Victim      string           `json:"victim,omitempty"`
+	Anonymous   bool             `json:"anonymous,omitempty"`  // attacker was the unauthenticated guest context
+	Credential  string           `json:"credential,omitempty"` // attacker credential set label
+	Diff        []DiffEntry      `json:"diff,omitempty"`       // fields differing from the attacker's own response (--diff)
+
+	SizeMismatch  bool   `json:"size_mismatch,omitempty"`  // a compared response's Content-Length disagreed with the bytes read
+	BaselineDrift bool   `json:"baseline_drift,omitempty"` // a drift check found the endpoint's baseline had shifted
+	ErrorClass    string `json:"error_class,omitempty"`    // INFO findings: why the request got no response (tls, dns, timeout, ...)
+	Sensitivity   string `json:"sensitivity,omitempty"`    // tag from the matching sensitivity rule
+
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"` // evidence headers on the triggering response
+	BaselineHeaders map[string]string `json:"baseline_headers,omitempty"` // the same headers on the victim's baseline
+}
+
+// RecordedRequest is the exact request that triggered a finding. Headers keep
+// their raw values, credentials included, so replay can resend them: JSON and
+// JSONL reports and checkpoints are written owner-only. Text, HTML and curl
+// output mask them (see redactHeader and redactQuery).
+type RecordedRequest struct {
+	Method    string      `json:"method"`
+	URL       string      `json:"url"`
+	Headers   http.Header `json:"headers,omitempty"`
+	Body      string      `json:"body,omitempty"`
+	AuthQuery []string    `json:"auth_query,omitempty"` // query parameters in URL that carry credentials
+}
+
+// maxSnippetSize caps how much of a response body is kept as evidence
+const maxSnippetSize = 1024
+
+// withExchange attaches the triggering request and a response snippet to a finding
+func withExchange(f *Finding, req *http.Request, body []byte) *Finding {
+	rec := &RecordedRequest{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: req.Header.Clone(),
+	}
+	if req.GetBody != nil {
+		if rc, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			rec.Body = string(data)
+		}
+	}
+	f.Request = rec
+
+	if len(body) > maxSnippetSize {
+		f.Response = string(body[:maxSnippetSize]) + "..."
+	} else {
+		f.Response = string(body)
+	}
+	return f
 }
 
 // Scanner performs IDOR testing
@@ -44,36 +210,143 @@ type Scanner struct {
 	Requests  []APIRequest
 	client    *http.Client
 	rateDelay time.Duration
+	enum      *EnumRange
+	siblings  int
+	sweepAll  bool // sweep write methods too (see SetEnumWrites)
+	typeFuzz  bool
+	fuzzPost  bool // type-fuzz POST requests too
+	cardSwap  bool
+	drift     *DriftCheck
+	onFinding func(Finding)
+	log       Logger
+	workers   int
+
+	maxFindings    int
+	stopOnCritical bool
+	found          int                // findings reported this run
+	limitHit       bool               // the finding limit stopped this run
+	stop           context.CancelFunc // cancels the current run
+	runCtx         context.Context    // the current run's context; ends throttle pauses
+	requestErrors  map[string]bool    // error findings already recorded this run
+
+	maxCredentials int
+	baselineHead   bool
+	diff           bool
+	sizeTolerance  int
+
+	similarity          string  // body metric for HIGH matches ("" = size tolerance)
+	similarityThreshold float64 // score at or above which a body matches
+
+	authQueryParams []string
+	evidenceHeaders []string    // response headers kept on baselines and findings
+	globalHeaders   http.Header // sent on every request unless a user overrides them
+	gatewayAuth     string      // edge basic auth, kept on no-auth requests
+
+	shuffle *rand.Rand // randomizes test order when set
+
+	checkpoint *checkpoint     // completed tests, for resuming (nil = off)
+	known      map[string]bool // accepted finding IDs, exempt from the finding limit
+
+	baselines  BaselineMap  // the current run's, updated by drift checks
+	baselineMu sync.RWMutex // guards baselines against concurrent drift checks
+
+	jarMu sync.Mutex
+	jars  map[string]http.CookieJar // per-user cookie jars, keyed by user name and credential
+
+	throttle *throttle
+	oauth    tokenCache
+
+	idLocations []IDLocation
+	sensitivity []SensitivityRule
+
+	maxIdleConns    int // idle connections kept per host (0 = sized to workers)
+	maxConnsPerHost int // 0 = no cap
+
+	forceHTTP2 bool     // fail requests that don't negotiate HTTP/2
+	h2c        bool     // cleartext HTTP/2 registered on the transport
+	protocols  sync.Map // host -> protocol of its last response
+
+	traffic       traffic
+	largeResponse int // body bytes above which a response is reported (0 = off)
 }
 
 // NewScanner creates a new scanner instance
 func NewScanner(users []User, requests []APIRequest) *Scanner {
 	return &Scanner{
-		Users:     users,
+		Users:     normalizeUsers(users),
 		Requests:  requests,
 		rateDelay: 100 * time.Millisecond, // Default 10 req/sec
+		jars:      make(map[string]http.CookieJar),
+		oauth:     tokenCache{tokens: make(map[string]oauthToken)},
+		throttle:  &throttle{threshold: 5, log: nopLogger{}},
+		log:       nopLogger{},
+		workers:   1,
+
+		maxCredentials: defaultMaxCredentials,
+		sizeTolerance:  defaultSizeTolerance,
+		largeResponse:  defaultLargeResponse,
+
+		similarityThreshold: defaultSimilarityThreshold,
+
+		authQueryParams: defaultAuthQueryParams,
+		evidenceHeaders: defaultEvidenceHeaders,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: newTransport(),
 		},
 	}
 }
 
-// SetProxy configures an HTTP proxy (e.g., Burp Suite)
+// SetProxy configures an HTTP proxy (e.g., Burp Suite) on the scanner's
+// transport, keeping its pool settings
 func (s *Scanner) SetProxy(proxyURL string) error {
+	return useProxy(s.transport(), proxyURL)
+}
+
+// useProxy routes t through proxyURL
+func useProxy(t *http.Transport, proxyURL string) error {
 	proxy, err := url.Parse(proxyURL)
 	if err != nil {
 		return err
 	}
 
-	s.client.Transport = &http.Transport{
-		Proxy: http.ProxyURL(proxy),
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true, // Required for Burp's self-signed cert
-		},
+	t.Proxy = http.ProxyURL(proxy)
+	t.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: true, // Required for Burp's self-signed cert
+	}
+	return nil
+}
+
+// SetProxyAuth adds Basic credentials to the configured proxy, whether set
+// with SetProxy or taken from the environment. The transport sends them as
+// Proxy-Authorization on plain requests and on HTTPS CONNECT.
+func (s *Scanner) SetProxyAuth(username, password string) error {
+	if username == "" {
+		return fmt.Errorf("proxy credentials need a username")
+	}
+	t, ok := s.client.Transport.(*http.Transport)
+	if !ok || t.Proxy == nil {
+		return fmt.Errorf("proxy credentials given without a proxy")
 	}
+	addProxyAuth(t, username, password)
 	return nil
 }
 
+// addProxyAuth attaches Basic credentials to every proxy URL t resolves
+func addProxyAuth(t *http.Transport, username, password string) {
+	proxy := t.Proxy
+	creds := url.UserPassword(username, password)
+	t.Proxy = func(req *http.Request) (*url.URL, error) {
+		u, err := proxy(req)
+		if u == nil || err != nil {
+			return u, err
+		}
+		withAuth := *u
+		withAuth.User = creds
+		return &withAuth, nil
+	}
+}
+
 // SetRateLimit sets requests per second
 func (s *Scanner) SetRateLimit(requestsPerSecond int) {
 	if requestsPerSecond > 0 {
@@ -81,108 +354,153 @@ func (s *Scanner) SetRateLimit(requestsPerSecond int) {
 	}
 }
 
-// Run executes the scan
-func (s *Scanner) Run() []Finding {
-	findings := []Finding{}
-
-	for _, req := range s.Requests {
-		if verbose {
-			fmt.Printf("🔍 Testing: %s %s\n", req.Method, req.URL)
-		}
-
-		// Test 1: Cross-user access (bidirectional)
-		for i, user1 := range s.Users {
-			for j, user2 := range s.Users {
-				if i == j {
-					continue
-				}
-
-				// Try to access user2's resources with user1's credentials
-				f := s.testCrossUserAccess(req, user1, user2)
-				if f != nil {
-					findings = append(findings, *f)
-				}
-			}
-		}
+// OnFinding registers a callback invoked as each finding is produced,
+// before the scan completes (used to stream results to disk)
+func (s *Scanner) OnFinding(fn func(Finding)) {
+	s.onFinding = fn
+}
 
-		// Test 2: No authentication
-		f := s.testNoAuth(req)
-		if f != nil {
-			findings = append(findings, *f)
-		}
+// addFinding appends f and notifies any OnFinding callback
+func (s *Scanner) addFinding(findings []Finding, f Finding) []Finding {
+	if f.ErrorClass != "" && s.seenRequestError(f) {
+		return findings
 	}
-
-	return findings
+	if f.ID == "" {
+		f.ID = findingID(f)
+	}
+	if s.checkpoint.wasRestored(f.ID) {
+		return findings
+	}
+	if proto := s.protocolFor(f); proto != "" && f.ErrorClass == "" {
+		f.Evidence += ", Protocol: " + proto
+	}
+	s.applySensitivity(&f)
+	s.noteAuthQuery(f.Request)
+	if s.baselines.drifted(f.Method + " " + f.Endpoint) {
+		f.BaselineDrift = true
+	}
+	s.checkpoint.record(f)
+	if s.onFinding != nil {
+		s.onFinding(f)
+	}
+	// Informational and already-accepted findings don't count toward the limit
+	if f.Severity != SeverityInfo && !s.known[f.ID] {
+		s.checkFindingLimit(f)
+	}
+	return append(findings, f)
 }
 
-func (s *Scanner) testCrossUserAccess(req APIRequest, attacker User, victim User) *Finding {
-	// Clone request and replace victim's params with attacker's auth
-	testReq := s.buildRequest(req, attacker, victim.Params)
+// testNoAuth replays the request without credentials. It targets a user
+// whose own response is personal (it differs from another user's) and flags
+// only a response that matches it, so public endpoints that answer everyone
+// alike are not reported.
+func (s *Scanner) testNoAuth(req APIRequest, baselines BaselineMap) *Finding {
+	endpoint := fmt.Sprintf("%s %s", req.Method, req.URL)
+	victim, baseline, ok := s.noAuthVictim(endpoint, baselines)
+	if !ok {
+		s.log.Warnf("⏭️  No-auth test skipped on %s: no user has a personal baseline (public or untestable)\n", endpoint)
+		return nil
+	}
+
+	url, body := s.personalize(req, victim)
+	testReq := s.buildRequestNoAuth(APIRequest{
+		Method:  req.Method,
+		URL:     url,
+		Headers: fillHeaders(req.Headers, victim.Params),
+		Body:    body,
+	})
 	if testReq == nil {
 		return nil
 	}
 
 	resp, err := s.executeRequest(testReq)
 	if err != nil {
-		if verbose {
-			fmt.Printf("   ⚠️  Error: %v\n", err)
-		}
-		return nil
+		return requestErrorFinding(req, "", "", err)
 	}
 	defer resp.Body.Close()
 
-	// Read response body for size comparison
-	body, _ := io.ReadAll(resp.Body)
+	respBody, mismatch := s.readBody(resp)
+	s.noteResponseSize(req, "", "", len(respBody))
 
-	// Check if attacker could access victim's resource
-	if resp.StatusCode == 200 || resp.StatusCode == 201 {
-		return &Finding{
-			Severity:    "CRITICAL",
-			Endpoint:    req.URL,
-			Method:      req.Method,
-			Description: fmt.Sprintf("User '%s' accessed resources belonging to '%s'", attacker.Name, victim.Name),
-			Evidence:    fmt.Sprintf("Status: %d, Size: %d bytes (expected 403/404)", resp.StatusCode, len(body)),
-			Timestamp:   time.Now(),
-		}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil
 	}
 
-	return nil
-}
+	severity, score := s.classifyCrossUser(resp.StatusCode, respBody, baseline)
+	evidence := fmt.Sprintf("Status: %d, Size: %d bytes ('%s's baseline: %d bytes)", resp.StatusCode, len(respBody), victim.Name, baseline.BodySize)
+	if score != "" {
+		evidence += ", " + score
+	}
+	var description string
 
-func (s *Scanner) testNoAuth(req APIRequest) *Finding {
-	// Clone request with no auth headers
-	testReq := s.buildRequestNoAuth(req)
-	if testReq == nil {
-		return nil
+	switch got, isList := extractItemIDs(respBody); {
+	case isList && len(baseline.ItemIDs) > 0:
+		leaked := containedItems(baseline.ItemIDs, nil, got)
+		if len(leaked) == 0 {
+			return nil
+		}
+		severity = SeverityCritical
+		description = fmt.Sprintf("Endpoint returned %d of '%s's items without authentication", len(leaked), victim.Name)
+		evidence = fmt.Sprintf("Status: %d, item IDs present: %s", resp.StatusCode, summarizeIDs(leaked))
+	case severity == SeverityCritical:
+		description = fmt.Sprintf("Endpoint returned '%s's data without authentication (matches their baseline)", victim.Name)
+	case severity == SeverityHigh:
+		description = fmt.Sprintf("Endpoint accessible without authentication (response the size of '%s's baseline)", victim.Name)
+	default:
+		leaked := leakedFields(baseline.Sensitive, nil, respBody)
+		if len(leaked) == 0 {
+			return nil
+		}
+		severity = SeverityHigh
+		description = fmt.Sprintf("Endpoint leaked %d of '%s's sensitive fields without authentication", len(leaked), victim.Name)
+		evidence = fmt.Sprintf("Status: %d, values present for: %s", resp.StatusCode, summarizeIDs(leaked))
 	}
 
-	resp, err := s.executeRequest(testReq)
-	if err != nil {
-		return nil
+	f := &Finding{
+		Kind:         kindNoAuth,
+		Severity:     severity,
+		Endpoint:     req.URL,
+		Method:       req.Method,
+		Description:  description,
+		Evidence:     evidence,
+		Timestamp:    time.Now(),
+		Victim:       victim.Name,
+		SizeMismatch: mismatch || baseline.SizeMismatch,
 	}
-	defer resp.Body.Close()
+	s.attachHeaders(f, resp, baseline)
+	return withExchange(f, testReq, respBody)
+}
 
-	body, _ := io.ReadAll(resp.Body)
+// noAuthVictim picks the first user whose baseline on endpoint is a usable
+// 2xx that differs from some other user's. A response every user gets alike
+// carries nobody's data, so a no-auth match against it proves nothing. With a
+// single user there is nothing to compare, so their own baseline is used.
+func (s *Scanner) noAuthVictim(endpoint string, baselines BaselineMap) (User, Baseline, bool) {
+	named := 0
+	for _, user := range s.Users {
+		if !user.IsAnonymous() {
+			named++
+		}
+	}
 
-	// Check if endpoint is accessible without auth
-	// Exclude common public endpoints
-	if resp.StatusCode == 200 && len(body) > 50 {
-		// Skip if response looks like an error page
-		bodyStr := string(body)
-		if strings.Contains(bodyStr, "unauthorized") || strings.Contains(bodyStr, "forbidden") {
-			return nil
+	for _, user := range s.Users {
+		own, ok := baselines[endpoint][user.Name]
+		if !ok || own.Denied || own.StatusCode < 200 || own.StatusCode >= 300 || own.BodySize == 0 {
+			continue
 		}
-		return &Finding{
-			Severity:    "HIGH",
-			Endpoint:    req.URL,
-			Method:      req.Method,
-			Description: "Endpoint accessible without authentication",
-			Evidence:    fmt.Sprintf("Status: %d, Response size: %d bytes", resp.StatusCode, len(body)),
-			Timestamp:   time.Now(),
+		if named == 1 {
+			return user, own, true
+		}
+		for name, other := range baselines[endpoint] {
+			if name == user.Name || other.Denied {
+				continue
+			}
+			if own.BodyHash != other.BodyHash || own.BodySize != other.BodySize {
+				return user, own, true
+			}
 		}
 	}
-
-	return nil
+	return User{}, Baseline{}, false
 }
 
 func (s *Scanner) buildRequest(req APIRequest, user User, params map[string]string) *http.Request {
@@ -205,23 +523,14 @@ func (s *Scanner) buildRequest(req APIRequest, user User, params map[string]stri
 
 	httpReq, err := http.NewRequest(req.Method, url, strings.NewReader(body))
 	if err != nil {
-		if verbose {
-			fmt.Printf("   ⚠️  Failed to build request: %v\n", err)
-		}
+		s.log.Debugf("   ⚠️  Failed to build request: %v\n", err)
 		return nil
 	}
 
-	// Add user's auth headers
-	for key, val := range user.Headers {
-		httpReq.Header.Set(key, val)
-	}
-
-	// Add original headers
-	for key, val := range req.Headers {
-		if _, exists := user.Headers[key]; !exists {
-			httpReq.Header.Set(key, val)
-		}
-	}
+	applyHeaders(httpReq, user.Headers, fillHeaders(req.Headers, user.Params))
+	s.applyGlobalHeaders(httpReq, user.Headers)
+	applyCookies(httpReq, user.Headers, user.Cookies, templateCookies(req.Headers, user.Params))
+	applyAuthParams(httpReq, user.AuthParams)
 
 	return httpReq
 }
@@ -229,31 +538,49 @@ func (s *Scanner) buildRequest(req APIRequest, user User, params map[string]stri
 // buildRequestWithSwap creates a request using attacker's auth to access victim's resources
 // This handles both placeholder replacement AND hardcoded ID swapping
 func (s *Scanner) buildRequestWithSwap(req APIRequest, attacker User, victim User) *http.Request {
+	if attacker.IsAnonymous() {
+		return s.buildAnonymousRequest(req, victim)
+	}
+
 	// Use improved ID swapping that handles hardcoded IDs
-	url := BuildSwappedURL(req.URL, attacker.Params, victim.Params)
-	body := BuildSwappedBody(req.Body, attacker.Params, victim.Params)
+	url, body := s.swappedRequest(req, attacker, victim)
 
 	httpReq, err := http.NewRequest(req.Method, url, strings.NewReader(body))
 	if err != nil {
-		if verbose {
-			fmt.Printf("   ⚠️  Failed to build request: %v\n", err)
-		}
+		s.log.Debugf("   ⚠️  Failed to build request: %v\n", err)
 		return nil
 	}
 
-	// Use ATTACKER's auth headers (this is the key - we're testing if attacker can access victim's data)
-	for key, val := range attacker.Headers {
-		httpReq.Header.Set(key, val)
-	}
+	// Use ATTACKER's auth headers (this is the key - we're testing if attacker can access victim's data).
+	// Header placeholders name the resource, so they get the victim's params;
+	// cookies are the session, so they get the attacker's.
+	applyHeaders(httpReq, attacker.Headers, fillHeaders(req.Headers, victim.Params))
+	s.applyGlobalHeaders(httpReq, attacker.Headers)
+	applyCookies(httpReq, attacker.Headers, attacker.Cookies, templateCookies(req.Headers, attacker.Params))
+	applyAuthParams(httpReq, attacker.AuthParams)
 
-	// Add original headers (non-auth ones)
-	for key, val := range req.Headers {
-		if _, exists := attacker.Headers[key]; !exists {
-			httpReq.Header.Set(key, val)
-		}
+	return httpReq
+}
+
+// swappedRequest returns the URL and body a swap request targets; anonymous
+// attackers get the victim's own URL and body
+func (s *Scanner) swappedRequest(req APIRequest, attacker, victim User) (string, string) {
+	if attacker.IsAnonymous() {
+		return s.personalize(req, victim)
 	}
+	return s.swapURL(req, attacker.Params, victim.Params), s.swapBody(req, attacker.Params, victim.Params)
+}
 
-	return httpReq
+// buildAnonymousRequest targets the victim's resources with every credential
+// stripped, including any captured in the original request
+func (s *Scanner) buildAnonymousRequest(req APIRequest, victim User) *http.Request {
+	url, body := s.personalize(req, victim)
+	return s.buildRequestNoAuth(APIRequest{
+		Method:  req.Method,
+		URL:     url,
+		Headers: fillHeaders(req.Headers, victim.Params),
+		Body:    body,
+	})
 }
 
 func (s *Scanner) buildRequestNoAuth(req APIRequest) *http.Request {
@@ -263,26 +590,371 @@ func (s *Scanner) buildRequestNoAuth(req APIRequest) *http.Request {
 	}
 
 	// Only add non-auth headers (exclude auth, cookie, session)
-	authKeywords := []string{"auth", "cookie", "session", "token", "x-api-key"}
-	for key, val := range req.Headers {
-		lowerKey := strings.ToLower(key)
-		isAuth := false
-		for _, kw := range authKeywords {
-			if strings.Contains(lowerKey, kw) {
-				isAuth = true
-				break
+	for key, vals := range req.Headers {
+		if !isAuthName(key) {
+			for _, val := range vals {
+				httpReq.Header.Add(key, val)
 			}
 		}
-		if !isAuth {
-			httpReq.Header.Set(key, val)
-		}
 	}
+	s.applyGlobalHeaders(httpReq, nil)
+
+	// Drop any query-string credentials baked into the captured URL
+	stripQueryParams(httpReq, s.noAuthQueryKeys())
 
 	return httpReq
 }
 
+// authWords mark header and field names that carry credentials. A name
+// matches when one of its words is in the list, so X-Auth-Token, session_id
+// and accessToken match but Author and tokenized don't.
+var authWords = map[string]bool{
+	"auth": true, "authorization": true, "authentication": true,
+	"cookie": true, "session": true, "token": true, "apikey": true,
+}
+
+// defaultAuthQueryParams are query parameter names stripped from no-auth requests
+var defaultAuthQueryParams = []string{"api_key", "token", "access_token", "key"}
+
+// isAuthName reports whether a header or field name looks like a credential
+func isAuthName(name string) bool {
+	words := nameWords(name)
+	for i, word := range words {
+		if authWords[word] || word == "api" && i+1 < len(words) && words[i+1] == "key" {
+			return true
+		}
+	}
+	return false
+}
+
+// nameWords splits a name into lower-case words at -, _ and . and at case
+// changes: X-CSRFToken is x, csrf, token
+func nameWords(name string) []string {
+	words := []string{}
+	start := 0
+	runes := []rune(name)
+	for i := 0; i <= len(runes); i++ {
+		split := i == len(runes) || runes[i] == '-' || runes[i] == '_' || runes[i] == '.'
+		if !split && i > start && unicode.IsUpper(runes[i]) {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			split = prevLower || unicode.IsUpper(runes[i-1]) && nextLower
+		}
+		if !split {
+			continue
+		}
+		if i > start {
+			words = append(words, strings.ToLower(string(runes[start:i])))
+		}
+		start = i
+		if i < len(runes) && !unicode.IsUpper(runes[i]) {
+			start = i + 1
+		}
+	}
+	return words
+}
+
+// SetAuthQueryParams sets the query parameter names treated as credentials
+// and stripped from no-auth requests, in addition to users' auth_params
+func (s *Scanner) SetAuthQueryParams(names []string) {
+	s.authQueryParams = names
+}
+
+// noAuthQueryKeys is the set of query parameter names stripped from an
+// unauthenticated request: users' auth_params and the configured auth names,
+// matched whole and case-insensitively
+func (s *Scanner) noAuthQueryKeys() map[string]bool {
+	known := make(map[string]bool)
+	for _, key := range append(s.authParamKeys(), s.authQueryParams...) {
+		known[strings.ToLower(key)] = true
+	}
+	return known
+}
+
+// noteAuthQuery records which of rec's query parameters are credentials
+// (see noAuthQueryKeys), so reports can mask them without the scanner
+func (s *Scanner) noteAuthQuery(rec *RecordedRequest) {
+	if rec == nil {
+		return
+	}
+	u, err := url.Parse(rec.URL)
+	if err != nil {
+		return
+	}
+	keys := s.noAuthQueryKeys()
+	rec.AuthQuery = nil
+	for name := range u.Query() {
+		if keys[strings.ToLower(name)] {
+			rec.AuthQuery = append(rec.AuthQuery, name)
+		}
+	}
+	sort.Strings(rec.AuthQuery)
+}
+
+// authParamKeys collects every query parameter name users authenticate with
+func (s *Scanner) authParamKeys() []string {
+	keys := []string{}
+	for _, user := range s.Users {
+		for key := range user.AuthParams {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// applyAuthParams sets the user's query-string credentials on the request URL.
+// The rest of the query is left as written: its order and any {placeholder}
+// survive, where re-encoding it would sort and escape them.
+func applyAuthParams(httpReq *http.Request, params map[string]string) {
+	if len(params) == 0 {
+		return
+	}
+	lower := make(map[string]bool, len(params))
+	for key := range params {
+		lower[strings.ToLower(key)] = true
+	}
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := splitQuery(httpReq.URL.RawQuery, lower)
+	for _, key := range keys {
+		pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(params[key]))
+	}
+	httpReq.URL.RawQuery = strings.Join(pairs, "&")
+}
+
+// stripQueryParams removes the named query parameters (lower-cased keys) from
+// the request URL, leaving the rest of the query as written
+func stripQueryParams(httpReq *http.Request, keys map[string]bool) {
+	if len(keys) == 0 || httpReq.URL.RawQuery == "" {
+		return
+	}
+	httpReq.URL.RawQuery = strings.Join(splitQuery(httpReq.URL.RawQuery, keys), "&")
+}
+
+// splitQuery splits a raw query into its key=value pairs, dropping those
+// whose unescaped key, lower-cased, is in drop
+func splitQuery(raw string, drop map[string]bool) []string {
+	pairs := []string{}
+	for _, pair := range strings.Split(raw, "&") {
+		if pair == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if !drop[strings.ToLower(key)] {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// applyHeaders sets the user's headers, then adds every original header value
+// the user doesn't override (repeated headers are preserved via Header.Add)
+func applyHeaders(httpReq *http.Request, userHeaders map[string]string, reqHeaders http.Header) {
+	for key, val := range userHeaders {
+		httpReq.Header.Set(key, val)
+	}
+
+	overridden := make(map[string]bool, len(userHeaders))
+	for key := range userHeaders {
+		overridden[http.CanonicalHeaderKey(key)] = true
+	}
+
+	for key, vals := range reqHeaders {
+		if overridden[http.CanonicalHeaderKey(key)] {
+			continue
+		}
+		for _, val := range vals {
+			httpReq.Header.Add(key, val)
+		}
+	}
+}
+
+// fillPlaceholders replaces {name} and {{name}} in s with params[name]
+func fillPlaceholders(s string, params map[string]string) string {
+	for key, val := range params {
+		s = strings.ReplaceAll(s, "{{"+key+"}}", val)
+		s = strings.ReplaceAll(s, "{"+key+"}", val)
+	}
+	return s
+}
+
+// fillHeaders returns a copy of reqHeaders with placeholders in their values
+// filled from params, as OpenAPI header parameters arrive as {name}
+func fillHeaders(reqHeaders http.Header, params map[string]string) http.Header {
+	filled := make(http.Header, len(reqHeaders))
+	for key, vals := range reqHeaders {
+		for _, val := range vals {
+			filled[key] = append(filled[key], fillPlaceholders(val, params))
+		}
+	}
+	return filled
+}
+
+// templateCookies returns the request's Cookie pairs that are placeholders
+// (OpenAPI cookie parameters), filled from params. Pairs left unfilled are
+// dropped rather than sent as literal {name}.
+func templateCookies(reqHeaders http.Header, params map[string]string) []string {
+	pairs := []string{}
+	for _, val := range reqHeaders.Values("Cookie") {
+		for _, pair := range strings.Split(val, ";") {
+			pair = strings.TrimSpace(pair)
+			if !strings.Contains(pair, "{") {
+				continue
+			}
+			if pair = fillPlaceholders(pair, params); !strings.Contains(pair, "{") {
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	return pairs
+}
+
+// cookieNames returns the cookie names set by Cookie header pairs
+func cookieNames(pairs []string) map[string]bool {
+	names := make(map[string]bool)
+	for _, val := range pairs {
+		for _, pair := range strings.Split(val, ";") {
+			name, _, _ := strings.Cut(pair, "=")
+			names[strings.TrimSpace(name)] = true
+		}
+	}
+	return names
+}
+
+// applyCookies replaces the request's Cookie header with the template's
+// cookie parameters (see templateCookies) followed by any Cookie value the
+// user set in headers and the user's cookies; the user's win on a name clash.
+// A captured Cookie header belongs to whoever recorded the traffic, so it is
+// never kept, even for a user with no cookies of their own.
+func applyCookies(httpReq *http.Request, userHeaders map[string]string, cookies map[string]string, template []string) {
+	pairs := []string{}
+	for key, val := range userHeaders {
+		if http.CanonicalHeaderKey(key) == "Cookie" && val != "" {
+			pairs = append(pairs, val)
+		}
+	}
+
+	names := make([]string, 0, len(cookies))
+	for name := range cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pairs = append(pairs, (&http.Cookie{Name: name, Value: cookies[name]}).String())
+	}
+
+	own := cookieNames(pairs)
+	merged := []string{}
+	for _, pair := range template {
+		if name, _, _ := strings.Cut(pair, "="); !own[name] {
+			merged = append(merged, pair)
+		}
+	}
+	pairs = append(merged, pairs...)
+
+	if len(pairs) == 0 {
+		httpReq.Header.Del("Cookie")
+		return
+	}
+	httpReq.Header.Set("Cookie", strings.Join(pairs, "; "))
+}
+
 func (s *Scanner) executeRequest(req *http.Request) (*http.Response, error) {
-	return s.client.Do(req)
+	return s.send(s.client, req)
+}
+
+// executeAs sends a request on behalf of a user. Each user gets an isolated
+// client with their own cookie jar, so session state set by one user's
+// responses never leaks into another user's requests across workers.
+func (s *Scanner) executeAs(user User, req *http.Request) (*http.Response, error) {
+	if err := s.applyOAuth(user, req); err != nil {
+		return nil, err
+	}
+	return s.send(s.clientFor(user), req)
+}
+
+// send honors any scan-wide 429 pause before sending, then feeds the response
+// status back into the throttle. With pausing on, a 429 is retried after the
+// pause (or its Retry-After) up to maxRetries429 times, so a throttled test
+// still gets a real answer.
+func (s *Scanner) send(client *http.Client, req *http.Request) (*http.Response, error) {
+	ctx := s.runContext()
+	for attempt := 1; ; attempt++ {
+		if err := s.throttle.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			s.countFailure(ctx)
+			return nil, err
+		}
+
+		s.countRequest(req, resp)
+		if err := s.checkProtocol(req, resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		s.noteProtocol(req, resp)
+		s.throttle.observe(resp)
+
+		if resp.StatusCode != http.StatusTooManyRequests || attempt > maxRetries429 || !s.throttle.enabled() {
+			return resp, nil
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req.Body = body
+		}
+		delay := s.throttle.retryDelay(resp, attempt)
+		resp.Body.Close()
+
+		s.log.Debugf("   ⏳ 429 on %s %s; retrying (%d/%d)\n", req.Method, req.URL, attempt, maxRetries429)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// runContext is the current run's context, or Background outside a scan
+func (s *Scanner) runContext() context.Context {
+	if s.runCtx == nil {
+		return context.Background()
+	}
+	return s.runCtx
+}
+
+// clientFor returns the user's client, sharing the scanner's transport and
+// timeout but keeping a per-user cookie jar
+func (s *Scanner) clientFor(user User) *http.Client {
+	key := user.Name + "\x00" + user.credential
+
+	s.jarMu.Lock()
+	jar, ok := s.jars[key]
+	if !ok {
+		jar, _ = cookiejar.New(nil)
+		s.jars[key] = jar
+	}
+	s.jarMu.Unlock()
+
+	return &http.Client{
+		Transport:     s.client.Transport,
+		Timeout:       s.client.Timeout,
+		CheckRedirect: s.client.CheckRedirect,
+		Jar:           jar,
+	}
 }
 
 func loadUsers(filename string) ([]User, error) {
@@ -300,5 +972,14 @@ func loadUsers(filename string) ([]User, error) {
 		return nil, err
 	}
 
+	// An invalid cookie would be dropped silently when the header is built
+	for _, u := range data.Users {
+		for name, val := range u.Cookies {
+			if err := (&http.Cookie{Name: name, Value: val}).Valid(); err != nil {
+				return nil, fmt.Errorf("user '%s': cookie %q: %w", u.Name, name, err)
+			}
+		}
+	}
+
 	return data.Users, nil
 }
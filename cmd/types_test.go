@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Each user's session cookies must only be sent back on that user's requests,
+// however the workers interleave them
+func TestSessionCookiesStayPerUser(t *testing.T) {
+	inner := selftestHandler()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token != "" {
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: token, Path: "/"})
+		}
+		inner.ServeHTTP(w, r)
+	})
+
+	for _, workers := range []int{1, 4} {
+		srv := newRecordingServer(t, handler)
+		requests := []APIRequest{
+			getRequest(srv.URL + "/api/users/123"),
+			getRequest(srv.URL + "/api/users/{user_id}/orders"),
+			getRequest(srv.URL + "/api/users/123"),
+		}
+		s := fastScanner(selftestUsers(), requests)
+		s.SetWorkers(workers)
+		if _, err := s.Scan(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		sent := 0
+		for _, r := range srv.requests() {
+			cookie := r.Header.Get("Cookie")
+			if cookie == "" {
+				continue
+			}
+			sent++
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if cookie != "sid="+token {
+				t.Errorf("workers=%d: %s %s as %q sent Cookie %q", workers, r.Method, r.Path, token, cookie)
+			}
+		}
+		if sent == 0 {
+			t.Errorf("workers=%d: no request sent a session cookie back", workers)
+		}
+	}
+}
+
+// An anonymous attacker sends none of the credentials captured in the request
+func TestAnonymousAttackerDropsCapturedAuth(t *testing.T) {
+	srv := newRecordingServer(t, selftestHandler())
+	req := getRequest(srv.URL + "/api/users/123")
+	req.Headers.Set("Authorization", "Bearer recorder-token")
+	req.Headers.Set("Cookie", "sid=recorder")
+
+	users := append(selftestUsers(), User{Name: "guest"})
+	s := fastScanner(users, []APIRequest{req})
+	if _, err := s.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range srv.requests() {
+		if strings.Contains(r.Header.Get("Authorization"), "recorder") || strings.Contains(r.Header.Get("Cookie"), "recorder") {
+			t.Errorf("%s %s sent the captured credentials: %v", r.Method, r.Path, r.Header)
+		}
+	}
+}
+
+func TestAttackersKeepPrimaryHeaders(t *testing.T) {
+	alice := User{
+		Name:    "alice",
+		Headers: map[string]string{"Authorization": "Bearer primary"},
+		Credentials: []CredentialSet{
+			{Label: "read-only", Headers: map[string]string{"Authorization": "Bearer r"}},
+			{Label: "admin", Headers: map[string]string{"Authorization": "Bearer a"}},
+			{Headers: map[string]string{"Authorization": "Bearer x"}},
+		},
+	}
+	bob := User{
+		Name:        "bob",
+		Credentials: []CredentialSet{{Label: "web", Headers: map[string]string{"Authorization": "Bearer b"}}},
+	}
+	log := &recordingLogger{}
+	s := NewScanner([]User{alice, bob}, nil)
+	s.SetLogger(log)
+
+	var got []string
+	for _, u := range s.attackers() {
+		got = append(got, u.Name+"/"+u.credential+"="+u.Headers["Authorization"])
+	}
+	want := []string{
+		"alice/=Bearer primary",
+		"alice/read-only=Bearer r",
+		"alice/admin=Bearer a",
+		"bob/web=Bearer b",
+	}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("attackers() = %v, want %v", got, want)
+	}
+
+	s.attackers()
+	s.warnCredentialCap()
+	if warns := log.warnings(); len(warns) != 1 || !strings.Contains(warns[0], "'alice' has 4 credential sets") {
+		t.Errorf("warnings = %q, want one cap warning for alice", warns)
+	}
+}
+
+func TestProxyAuth(t *testing.T) {
+	proxy := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("proxied"))
+	}))
+
+	s := fastScanner(nil, nil)
+	if err := s.SetProxy(proxy.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetProxyAuth("burp", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := s.executeRequest(s.buildRequest(getRequest("http://api.example.invalid/users/1"), User{}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	seen := proxy.requests()
+	if len(seen) != 1 {
+		t.Fatalf("proxy got %d requests, want 1", len(seen))
+	}
+	// base64("burp:s3cret")
+	if got := seen[0].Header.Get("Proxy-Authorization"); got != "Basic YnVycDpzM2NyZXQ=" {
+		t.Errorf("Proxy-Authorization = %q", got)
+	}
+}
+
+func TestProxyAuthNeedsUsername(t *testing.T) {
+	s := fastScanner(nil, nil)
+	if err := s.SetProxy("http://127.0.0.1:8080"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetProxyAuth("", "s3cret"); err == nil {
+		t.Error("SetProxyAuth accepted a password without a username")
+	}
+}
+
+func TestIsAuthName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Authorization":        true,
+		"Proxy-Authorization":  true,
+		"X-Auth-Token":         true,
+		"X-API-Key":            true,
+		"x-api-key":            true,
+		"X-CSRFToken":          true,
+		"Cookie":               true,
+		"session_id":           true,
+		"accessToken":          true,
+		"apikey":               true,
+		"author_id":            false,
+		"tokenized":            false,
+		"X-Author":             false,
+		"Accept":               false,
+		"keyword":              false,
+		"X-Request-Id":         false,
+		"authenticated_before": false,
+	} {
+		if got := isAuthName(name); got != want {
+			t.Errorf("isAuthName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestNoAuthStripsOnlyAuthQueryParams(t *testing.T) {
+	s := fastScanner(nil, nil)
+	s.SetAuthQueryParams(defaultAuthQueryParams)
+	req := s.buildRequestNoAuth(getRequest("http://api.test/search?z=1&api_key=secret&author_id=7&tokenized=yes&q={term}&API_KEY=x&a=2"))
+	if got, want := req.URL.RawQuery, "z=1&author_id=7&tokenized=yes&q={term}&a=2"; got != want {
+		t.Errorf("query = %q, want %q", got, want)
+	}
+}
+
+func TestApplyAuthParamsKeepsQuery(t *testing.T) {
+	user := User{Name: "alice", AuthParams: map[string]string{"api_key": "alice key"}}
+	s := fastScanner([]User{user}, nil)
+	req := s.buildRequest(getRequest("http://api.test/search?z=1&api_key=recorder&q={term}"), user, nil)
+	if got, want := req.URL.RawQuery, "z=1&q={term}&api_key=alice+key"; got != want {
+		t.Errorf("query = %q, want %q", got, want)
+	}
+}
+
+func TestMultipleCookies(t *testing.T) {
+	user := User{
+		Name:    "alice",
+		Headers: map[string]string{"Cookie": "theme=dark"},
+		Cookies: map[string]string{"sid": "s%3Aab12", "csrf": "f9e1"},
+	}
+	s := fastScanner([]User{user}, nil)
+	req := getRequest("http://api.test/users/1")
+	req.Headers.Set("Cookie", "sid=recorder")
+
+	if got, want := s.buildRequest(req, user, nil).Header.Get("Cookie"), "theme=dark; csrf=f9e1; sid=s%3Aab12"; got != want {
+		t.Errorf("Cookie = %q, want %q", got, want)
+	}
+	if got := s.buildRequestNoAuth(req).Header.Get("Cookie"); got != "" {
+		t.Errorf("no-auth Cookie = %q, want none", got)
+	}
+}
+
+func TestLoadUsersRejectsInvalidCookieNames(t *testing.T) {
+	good := writeTemp(t, "good.json", `{"users":[{"name":"alice","cookies":{"sid":"a","csrf":"b"}}]}`)
+	if _, err := loadUsers(good); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"bad name", "semi;colon", ""} {
+		path := writeTemp(t, "users.json", fmt.Sprintf(`{"users":[{"name":"alice","cookies":{%q:"a"}}]}`, name))
+		if _, err := loadUsers(path); err == nil {
+			t.Errorf("loadUsers accepted cookie name %q", name)
+		}
+	}
+}
+
+// With one user there is no second baseline to compare, so the no-auth test
+// grades against that user's own
+func TestNoAuthSingleUser(t *testing.T) {
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"123","name":"Alice","email":"alice@example.com"}`)
+	}))
+	req := getRequest(srv.URL + "/api/users/{user_id}")
+	s := fastScanner(selftestUsers()[:1], []APIRequest{req})
+
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Kind != kindNoAuth {
+		t.Fatalf("want one no-auth finding, got %+v", findings)
+	}
+}
+
+// Skipping the no-auth test on an endpoint is surfaced, not just debug output
+func TestNoAuthSkipWarns(t *testing.T) {
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":"ok","version":"1.2.3"}`)
+	}))
+	s := fastScanner(selftestUsers(), []APIRequest{getRequest(srv.URL + "/health")})
+	log := &recordingLogger{}
+	s.SetLogger(log)
+
+	if _, err := s.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	warns := log.warnings()
+	if len(warns) != 1 || !strings.Contains(warns[0], "No-auth test skipped") {
+		t.Errorf("warnings = %q", warns)
+	}
+}
+
+// A pair whose swap leaves the attacker's own request isn't tested, but two
+// users whose own objects look alike still catch a real IDOR
+func TestSwaplessPairsSkipped(t *testing.T) {
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/health" {
+			fmt.Fprint(w, `{"status":"ok"}`)
+			return
+		}
+		// Every account starts out the same, and any user can read any account
+		fmt.Fprint(w, `{"balance":0,"currency":"EUR","iban":"DE00 0000 0000"}`)
+	}))
+	requests := []APIRequest{
+		getRequest(srv.URL + "/health"),
+		getRequest(srv.URL + "/api/accounts/{user_id}"),
+	}
+
+	for _, workers := range []int{1, 4} {
+		s := fastScanner(selftestUsers(), requests)
+		s.SetWorkers(workers)
+		findings, err := s.Scan(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		crossUser := 0
+		for _, f := range findings {
+			if f.Kind != kindCrossUser {
+				continue
+			}
+			if strings.HasSuffix(f.Endpoint, "/health") {
+				t.Errorf("workers=%d: /health reported: %+v", workers, f)
+			} else {
+				crossUser++
+			}
+		}
+		if crossUser == 0 {
+			t.Errorf("workers=%d: IDOR on identical-looking accounts not reported: %+v", workers, findings)
+		}
+	}
+}
+
+// A credential-less user attacks as the guest context: its findings are
+// labelled anonymous, and it never has a baseline or plays the victim
+func TestGuestContext(t *testing.T) {
+	srv := newRecordingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Profiles are served to anyone, signed in or not
+		fmt.Fprintf(w, `{"path":%q,"email":"someone@example.com"}`, r.URL.Path)
+	}))
+	users := append(selftestUsers(), User{Name: "guest"})
+	s := fastScanner(users, []APIRequest{getRequest(srv.URL + "/api/users/{user_id}")})
+
+	findings, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	guest := 0
+	for _, f := range findings {
+		if f.Victim == "guest" {
+			t.Errorf("guest was a victim: %+v", f)
+		}
+		if f.Attacker != "guest" {
+			continue
+		}
+		guest++
+		if !f.Anonymous || !strings.Contains(f.Description, "Anonymous user 'guest'") {
+			t.Errorf("guest finding not labelled anonymous: %+v", f)
+		}
+	}
+	if guest == 0 {
+		t.Errorf("no finding as the guest context: %+v", findings)
+	}
+}
+
+// can_attack and can_be_victim remove a user from one side of the pairs only
+func TestUserRoles(t *testing.T) {
+	path := writeTemp(t, "users.json", `{"users": [
+		{"name": "alice", "headers": {"Authorization": "Bearer a"}},
+		{"name": "admin", "headers": {"Authorization": "Bearer b"}, "can_be_victim": false},
+		{"name": "service", "headers": {"Authorization": "Bearer c"}, "can_attack": false}
+	]}`)
+	users, err := loadUsers(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, p := range NewScanner(users, nil).testPairs() {
+		got = append(got, p.attacker.Name+"→"+p.victim.Name)
+	}
+	want := []string{"alice→service", "admin→alice", "admin→service"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("pairs = %q, want %q", got, want)
+	}
+}
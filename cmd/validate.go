@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the users file, config and input collection without scanning",
+	Long: `Validate parses the selected input source and users file, reports what was
+loaded, and flags placeholders or path IDs that no user can fill. Catches the
+"scan ran but everything 404'd" class of problems before any traffic is sent.`,
+	Run: runValidate,
+}
+
+func init() {
+	addInputFlags(validateCmd)
+	rootCmd.AddCommand(validateCmd)
+}
+
+// placeholderPattern matches {name}, {{name}} and /:name placeholders
+var placeholderPattern = regexp.MustCompile(`\{\{?([A-Za-z_][\w.-]*)\}?\}|/:([A-Za-z_]\w*)`)
+
+// findPlaceholders returns the distinct placeholder names used in s
+func findPlaceholders(s string) []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, m := range placeholderPattern.FindAllStringSubmatch(s, -1) {
+		name := m[1]
+		if name == "" {
+			name = m[2]
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ValidationReport collects problems found by validateSetup
+type ValidationReport struct {
+	Errors   []string
+	Warnings []string
+}
+
+// validateSetup checks that users and requests line up for cross-user testing
+func validateSetup(users []User, requests []APIRequest) ValidationReport {
+	report := ValidationReport{}
+
+	if len(users) == 0 {
+		report.Errors = append(report.Errors, "users file defines no users")
+	} else if len(users) < 2 {
+		report.Warnings = append(report.Warnings, "only one user defined; cross-user tests need at least two")
+	}
+
+	names := make(map[string]bool)
+	for _, u := range users {
+		if u.Name == "" {
+			report.Errors = append(report.Errors, "a user is missing a name")
+			continue
+		}
+		if names[u.Name] {
+			report.Errors = append(report.Errors, fmt.Sprintf("duplicate user name %q (baselines are keyed by name)", u.Name))
+		}
+		names[u.Name] = true
+
+		if u.OAuth != nil && (u.OAuth.TokenEndpoint == "" || u.OAuth.ClientID == "") {
+			report.Errors = append(report.Errors, fmt.Sprintf("user '%s': oauth needs token_endpoint and client_id", u.Name))
+		}
+	}
+
+	report.Warnings = append(report.Warnings, sharedIdentityWarnings(users)...)
+
+	if len(requests) == 0 {
+		report.Errors = append(report.Errors, "input source contains no requests")
+	}
+
+	unresolved := make(map[string][]string)     // placeholder -> endpoints
+	missing := make(map[string]map[string]bool) // user -> missing params
+
+	for _, req := range requests {
+		endpoint := fmt.Sprintf("%s %s", req.Method, req.URL)
+
+		for _, name := range findPlaceholders(req.URL + "\n" + req.Body) {
+			lacking := []string{}
+			for _, u := range users {
+				if u.IsAnonymous() {
+					continue // guest context borrows the victim's IDs
+				}
+				if _, ok := u.Params[name]; !ok {
+					lacking = append(lacking, u.Name)
+				}
+			}
+
+			// Nobody can fill it: unresolved. Some can't: per-user warning.
+			if len(lacking) == len(users)-countAnonymous(users) {
+				unresolved[name] = append(unresolved[name], endpoint)
+				continue
+			}
+			for _, userName := range lacking {
+				if missing[userName] == nil {
+					missing[userName] = make(map[string]bool)
+				}
+				missing[userName][name] = true
+			}
+		}
+
+		// Hardcoded path IDs only swap when some user's params own them
+		for _, id := range ExtractIDsFromURL(req.URL) {
+			if strings.HasPrefix(id.Value, "{") {
+				continue
+			}
+			owned := false
+			for _, u := range users {
+				for _, val := range u.Params {
+					if val == id.Value {
+						owned = true
+					}
+				}
+			}
+			if !owned {
+				report.Warnings = append(report.Warnings,
+					fmt.Sprintf("%s: path ID %q (after /%s/) matches no user's params, so it won't be swapped", endpoint, id.Value, id.Key))
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(unresolved) {
+		eps := unresolved[name]
+		report.Warnings = append(report.Warnings,
+			fmt.Sprintf("unresolved placeholder {%s} in %d request(s), e.g. %s", name, len(eps), eps[0]))
+	}
+
+	for _, u := range users {
+		params := missing[u.Name]
+		if len(params) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(params))
+		for k := range params {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		report.Warnings = append(report.Warnings,
+			fmt.Sprintf("user '%s' has no params for: %s", u.Name, strings.Join(keys, ", ")))
+	}
+
+	return report
+}
+
+func countAnonymous(users []User) int {
+	n := 0
+	for _, u := range users {
+		if u.IsAnonymous() {
+			n++
+		}
+	}
+	return n
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func runValidate(cmd *cobra.Command, args []string) {
+	fatal := false
+
+	if !hasInputSource() {
+		fmt.Fprintln(os.Stderr, "Error: must specify at least one of --collection, --openapi, --har, or --requests-dir")
+		os.Exit(1)
+	}
+
+	users, err := loadUsers(usersFile)
+	if err != nil {
+		fmt.Printf("❌ Users file: %v\n", err)
+		fatal = true
+	} else {
+		fmt.Printf("✅ Loaded %d user contexts from %s\n", len(users), usersFile)
+	}
+
+	requests, err := loadRequests()
+	if err != nil {
+		fmt.Printf("❌ Input: %v\n", err)
+		fatal = true
+	} else {
+		fmt.Printf("✅ Loaded %d API requests\n", len(requests))
+	}
+
+	if fatal {
+		os.Exit(1)
+	}
+
+	report := validateSetup(users, requests)
+
+	// Config-file sections that the scanner would reject at startup
+	var idLocations []IDLocation
+	if err := viper.UnmarshalKey("id_locations", &idLocations); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("config id_locations: %v", err))
+	} else if err := NewScanner(users, requests).SetIDLocations(idLocations); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("config: %v", err))
+	}
+	if err := SetSeverityLabels(viper.GetStringMapString("severity_labels")); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("config: %v", err))
+	}
+	var sensitivity []SensitivityRule
+	if err := viper.UnmarshalKey("sensitivity", &sensitivity); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("config sensitivity: %v", err))
+	} else if err := NewScanner(users, requests).SetSensitivity(sensitivity); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("config: %v", err))
+	}
+
+	fmt.Println()
+
+	for _, e := range report.Errors {
+		fmt.Printf("❌ %s\n", e)
+	}
+	for _, w := range report.Warnings {
+		fmt.Printf("⚠️  %s\n", w)
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Printf("\n📊 Validation failed: %d errors, %d warnings\n", len(report.Errors), len(report.Warnings))
+		os.Exit(1)
+	}
+	fmt.Printf("📊 Validation passed with %d warnings\n", len(report.Warnings))
+}
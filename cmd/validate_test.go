@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindPlaceholders(t *testing.T) {
+	got := findPlaceholders(`/api/users/{user_id}/orders/:order_id?x={{token}}` + "\n" + `{"user":"{user_id}"}`)
+	want := []string{"user_id", "order_id", "token"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("findPlaceholders = %q, want %q", got, want)
+	}
+}
+
+func TestValidateSetup(t *testing.T) {
+	users := []User{
+		{Name: "alice", Headers: map[string]string{"Authorization": "Bearer a"}, Params: map[string]string{"user_id": "123", "order_id": "9"}},
+		{Name: "bob", Headers: map[string]string{"Authorization": "Bearer b"}, Params: map[string]string{"user_id": "456"}},
+		{Name: "bob", Headers: map[string]string{"Authorization": "Bearer c"}},
+	}
+	requests := []APIRequest{
+		getRequest("https://api.example.com/api/users/{user_id}/orders/{order_id}"),
+		getRequest("https://api.example.com/api/users/{tenant}"),
+		getRequest("https://api.example.com/api/orders/777"),
+	}
+
+	report := validateSetup(users, requests)
+
+	if len(report.Errors) != 1 || !strings.Contains(report.Errors[0], `duplicate user name "bob"`) {
+		t.Errorf("errors = %q", report.Errors)
+	}
+	for _, want := range []string{
+		"unresolved placeholder {tenant}",
+		"user 'bob' has no params for: order_id",
+		`path ID "777" (after /orders/) matches no user's params`,
+	} {
+		found := false
+		for _, w := range report.Warnings {
+			if strings.Contains(w, want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no warning containing %q in %q", want, report.Warnings)
+		}
+	}
+}
+
+func TestValidateSetupEmpty(t *testing.T) {
+	report := validateSetup(nil, nil)
+	if len(report.Errors) != 2 {
+		t.Errorf("errors = %q, want missing users and requests", report.Errors)
+	}
+}
@@ -0,0 +1,62 @@
+// Package testserver is the deliberately vulnerable demo API behind
+// examples/test-server.go and the selftest command.
+package testserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Handler serves the demo API: any authenticated caller can read any user's
+// profile and orders, and /health is public
+func Handler() http.Handler {
+	// Simulated database
+	users := map[string]map[string]interface{}{
+		"123": {"id": "123", "name": "Alice", "email": "alice@example.com", "ssn": "111-22-3333"},
+		"456": {"id": "456", "name": "Bob", "email": "bob@example.com", "ssn": "444-55-6666"},
+	}
+	orders := map[string][]map[string]interface{}{
+		"123": {{"id": "order-1", "amount": 99.99, "item": "Secret Alice Item"}},
+		"456": {{"id": "order-2", "amount": 149.99, "item": "Secret Bob Item"}},
+	}
+
+	mux := http.NewServeMux()
+	// VULNERABLE: No authorization check - just checks if token exists
+	mux.HandleFunc("/api/users/", func(w http.ResponseWriter, r *http.Request) {
+		// Extract user_id from path
+		parts := strings.Split(r.URL.Path, "/")
+		if len(parts) < 4 {
+			http.Error(w, "Not found", 404)
+			return
+		}
+		userID := parts[3]
+
+		// Check auth header exists (but NOT if it matches the user!)
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, `{"error": "unauthorized"}`, 401)
+			return
+		}
+
+		// VULNERABLE: Returns any user's orders or profile if authenticated
+		var data interface{}
+		var ok bool
+		if len(parts) > 4 && parts[4] == "orders" {
+			data, ok = orders[userID]
+		} else {
+			data, ok = users[userID]
+		}
+		if !ok {
+			http.Error(w, "Not found", 404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(data)
+	})
+
+	// Health check (intentionally public)
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	return mux
+}